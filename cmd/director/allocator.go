@@ -0,0 +1,182 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrNoCapacity is returned when every server of a region is in use.
+	ErrNoCapacity = errors.New("no game server capacity")
+	// ErrAllocationFailed is returned when a server failed to start.
+	ErrAllocationFailed = errors.New("game server allocation failed")
+)
+
+// Allocation is a game server handed out for a match.
+type Allocation struct {
+	Region  string
+	Address string
+}
+
+// GameServerAllocator hands out game servers for matches and takes them back
+// once the game ends.
+type GameServerAllocator interface {
+	// Allocate returns a server in the region, an empty region picks the one
+	// with the most free servers.
+	Allocate(ctx context.Context, region string) (Allocation, error)
+	Release(allocation Allocation)
+}
+
+// FleetConfig controls the simulated fleet.
+type FleetConfig struct {
+	Regions []string
+	// Capacity is the number of servers in every region.
+	Capacity int
+	// WarmServers is how many free servers a region keeps running, the rest
+	// are shut down and take ColdLatency instead of WarmLatency to allocate.
+	WarmServers int
+	WarmLatency time.Duration
+	ColdLatency time.Duration
+	// FailureChance is the chance an allocation fails after its latency.
+	FailureChance float64
+}
+
+type fleetServer struct {
+	address   string
+	warm      bool
+	allocated bool
+}
+
+// Fleet is a GameServerAllocator with a fixed number of simulated servers
+// per region.
+type Fleet struct {
+	config  FleetConfig
+	mu      sync.Mutex
+	regions map[string][]*fleetServer
+	rng     *rand.Rand
+}
+
+func NewFleet(config FleetConfig, r *rand.Rand) *Fleet {
+	f := &Fleet{
+		config:  config,
+		regions: make(map[string][]*fleetServer),
+		rng:     r,
+	}
+	for regionIndex, region := range config.Regions {
+		servers := []*fleetServer{}
+		for i := 0; i < config.Capacity; i++ {
+			servers = append(servers, &fleetServer{
+				address: fmt.Sprintf("10.%d.%d.%d:7777", regionIndex, i/256, i%256),
+				warm:    i < config.WarmServers,
+			})
+		}
+		f.regions[region] = servers
+	}
+	return f
+}
+
+func (f *Fleet) Allocate(ctx context.Context, region string) (Allocation, error) {
+	if err := ctx.Err(); err != nil {
+		return Allocation{}, err
+	}
+	f.mu.Lock()
+	if region == "" {
+		region = f.freestRegion()
+	}
+	servers, ok := f.regions[region]
+	if !ok {
+		f.mu.Unlock()
+		return Allocation{}, fmt.Errorf("unknown region %q", region)
+	}
+
+	// Warm servers are preferred as they start faster.
+	var server *fleetServer
+	for _, s := range servers {
+		if !s.allocated && (server == nil || s.warm && !server.warm) {
+			server = s
+		}
+	}
+	if server == nil {
+		f.mu.Unlock()
+		return Allocation{}, fmt.Errorf("%w in region %s", ErrNoCapacity, region)
+	}
+	server.allocated = true
+	latency := f.config.ColdLatency
+	if server.warm {
+		latency = f.config.WarmLatency
+	}
+	failed := f.rng.Float64() < f.config.FailureChance
+	f.mu.Unlock()
+
+	allocation := Allocation{Region: region, Address: server.address}
+	timer := time.NewTimer(latency)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		f.Release(allocation)
+		return Allocation{}, ctx.Err()
+	case <-timer.C:
+	}
+
+	if failed {
+		f.Release(allocation)
+		return Allocation{}, fmt.Errorf("%w in region %s", ErrAllocationFailed, region)
+	}
+	f.mu.Lock()
+	server.warm = true
+	f.mu.Unlock()
+	return allocation, nil
+}
+
+// Release frees the server, it stays warm while the region has fewer warm
+// free servers than configured.
+func (f *Fleet) Release(allocation Allocation) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	warm := 0
+	var released *fleetServer
+	for _, s := range f.regions[allocation.Region] {
+		if s.address == allocation.Address {
+			released = s
+		} else if !s.allocated && s.warm {
+			warm++
+		}
+	}
+	if released == nil || !released.allocated {
+		return
+	}
+	released.allocated = false
+	released.warm = warm < f.config.WarmServers
+}
+
+// Free returns the number of unallocated servers in the region.
+func (f *Fleet) Free(region string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.free(region)
+}
+
+func (f *Fleet) free(region string) int {
+	free := 0
+	for _, s := range f.regions[region] {
+		if !s.allocated {
+			free++
+		}
+	}
+	return free
+}
+
+func (f *Fleet) freestRegion() string {
+	best, bestFree := "", -1
+	for _, region := range f.config.Regions {
+		if free := f.free(region); free > bestFree {
+			best, bestFree = region, free
+		}
+	}
+	return best
+}
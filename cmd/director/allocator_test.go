@@ -0,0 +1,148 @@
+package main
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	utils "sim/internal"
+	"sim/internal/random"
+	"sim/internal/scenario"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"open-match.dev/open-match/pkg/pb"
+)
+
+func TestFleet(t *testing.T) {
+	fleet := NewFleet(FleetConfig{Regions: []string{"europe", "us"}, Capacity: 2, WarmServers: 1, ColdLatency: 50 * time.Millisecond}, random.New(1))
+
+	// The warm server is handed out first and without latency.
+	start := time.Now()
+	warm, err := fleet.Allocate(context.Background(), "europe")
+	require.NoError(t, err)
+	require.Less(t, time.Since(start), 50*time.Millisecond)
+	require.Equal(t, "europe", warm.Region)
+
+	start = time.Now()
+	cold, err := fleet.Allocate(context.Background(), "europe")
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, time.Since(start), 50*time.Millisecond)
+	require.NotEqual(t, warm.Address, cold.Address)
+
+	_, err = fleet.Allocate(context.Background(), "europe")
+	require.ErrorIs(t, err, ErrNoCapacity)
+	_, err = fleet.Allocate(context.Background(), "asia")
+	require.Error(t, err)
+
+	// Without a region the region with the most free servers is used.
+	picked, err := fleet.Allocate(context.Background(), "")
+	require.NoError(t, err)
+	require.Equal(t, "us", picked.Region)
+
+	fleet.Release(cold)
+	fleet.Release(cold)
+	require.Equal(t, 1, fleet.Free("europe"))
+	again, err := fleet.Allocate(context.Background(), "europe")
+	require.NoError(t, err)
+	require.Equal(t, cold.Address, again.Address)
+}
+
+func TestFleetFailures(t *testing.T) {
+	fleet := NewFleet(FleetConfig{Regions: []string{"europe"}, Capacity: 1, FailureChance: 1}, random.New(1))
+	_, err := fleet.Allocate(context.Background(), "europe")
+	require.ErrorIs(t, err, ErrAllocationFailed)
+	require.Equal(t, 1, fleet.Free("europe"))
+
+	fleet = NewFleet(FleetConfig{Regions: []string{"europe"}, Capacity: 1, ColdLatency: time.Hour}, random.New(1))
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = fleet.Allocate(ctx, "europe")
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	require.Equal(t, 1, fleet.Free("europe"))
+}
+
+// fakeBackend records the assigned and released tickets.
+type fakeBackend struct {
+	pb.BackendServiceClient
+	mu       sync.Mutex
+	assigned map[string]string
+	released []string
+}
+
+func (b *fakeBackend) AssignTickets(ctx context.Context, req *pb.AssignTicketsRequest, opts ...grpc.CallOption) (*pb.AssignTicketsResponse, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, group := range req.GetAssignments() {
+		for _, id := range group.GetTicketIds() {
+			b.assigned[id] = group.GetAssignment().GetConnection()
+		}
+	}
+	return &pb.AssignTicketsResponse{}, nil
+}
+
+func (b *fakeBackend) ReleaseTickets(ctx context.Context, req *pb.ReleaseTicketsRequest, opts ...grpc.CallOption) (*pb.ReleaseTicketsResponse, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.released = append(b.released, req.GetTicketIds()...)
+	return &pb.ReleaseTicketsResponse{}, nil
+}
+
+func testTicket(id string) *pb.Ticket {
+	return &pb.Ticket{Id: id, SearchFields: &pb.SearchFields{DoubleArgs: map[string]float64{utils.GSkillArg: 100}}}
+}
+
+func TestAssignerQueue(t *testing.T) {
+	config := scenario.Default()
+	config.Regions = []string{"europe"}
+	config.Modes[0].Backfill = false
+	profile := profilesCall(newScenario(config))[0]
+
+	be := &fakeBackend{assigned: map[string]string{}}
+	fleet := NewFleet(FleetConfig{Regions: []string{"europe"}, Capacity: 1}, random.New(1))
+	assigner := &matchAssigner{
+		be:           be,
+		allocator:    fleet,
+		servers:      GameServerConfig{GameDuration: time.Hour, AcknowledgeInterval: time.Hour},
+		queueTimeout: time.Hour,
+		rng:          random.NewLocked(1),
+	}
+
+	first := &pb.Match{MatchId: "first", Tickets: []*pb.Ticket{testTicket("a")}}
+	second := &pb.Match{MatchId: "second", Tickets: []*pb.Ticket{testTicket("b")}}
+	assigner.assign([]*pb.Match{first, second}, profile)
+	assigner.wait()
+	require.Len(t, be.assigned, 1)
+	require.Len(t, assigner.queue, 1)
+	queued := assigner.queue[0].match.GetTickets()[0].GetId()
+
+	// The queued match starts once the first game gave its server back.
+	assigner.retryQueued()
+	assigner.wait()
+	require.Len(t, assigner.queue, 1)
+	fleet.Release(Allocation{Region: "europe", Address: "10.0.0.0:7777"})
+	assigner.retryQueued()
+	assigner.wait()
+	require.Empty(t, assigner.queue)
+	require.Equal(t, "10.0.0.0:7777", be.assigned[queued])
+
+	// Matches that wait too long are given back to Open Match.
+	assigner.queueTimeout = 0
+	third := &pb.Match{MatchId: "third", Tickets: []*pb.Ticket{testTicket("c"), testTicket("d")}}
+	assigner.assign([]*pb.Match{third}, profile)
+	assigner.wait()
+	require.Empty(t, assigner.queue)
+	require.Equal(t, []string{"c", "d"}, be.released)
+
+	// A slow allocation does not hold up the caller and gives up once the
+	// match waited too long.
+	assigner.allocator = NewFleet(FleetConfig{Regions: []string{"europe"}, Capacity: 1, ColdLatency: time.Hour}, random.New(1))
+	assigner.queueTimeout = 50 * time.Millisecond
+	start := time.Now()
+	assigner.assign([]*pb.Match{{MatchId: "slow", Tickets: []*pb.Ticket{testTicket("e")}}}, profile)
+	require.Less(t, time.Since(start), assigner.queueTimeout)
+	assigner.wait()
+	require.Empty(t, assigner.queue)
+	require.Equal(t, []string{"c", "d", "e"}, be.released)
+}
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	utils "sim/internal"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"gopkg.in/yaml.v3"
+	"open-match.dev/open-match/pkg/pb"
+)
+
+// runProfiles implements "director profiles", which generates the profiles of
+// a scenario without connecting to Open Match, checks them and optionally
+// prints them. It returns the exit code.
+func runProfiles(args []string, stdout io.Writer, stderr io.Writer) int {
+	flags := flag.NewFlagSet("profiles", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	scenarioPath := flags.String("scenario", "", "YAML or JSON file with the regions, modes and lobbies to generate profiles for")
+	dump := flags.Bool("dump", false, "print the generated profiles")
+	format := flags.String("format", "json", "format of the dump: json or yaml")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if *format != "json" && *format != "yaml" {
+		fmt.Fprintf(stderr, "Unknown format %q, expected json or yaml\n", *format)
+		return 2
+	}
+
+	scenarioConfig, err := loadScenario(*scenarioPath)
+	if err != nil {
+		fmt.Fprintf(stderr, "Failed to load scenario, got %s\n", err.Error())
+		return 1
+	}
+	profiles := profilesCall(newScenario(scenarioConfig))
+
+	if *dump {
+		data, err := dumpProfiles(profiles, *format)
+		if err != nil {
+			fmt.Fprintf(stderr, "Failed to encode profiles, got %s\n", err.Error())
+			return 1
+		}
+		stdout.Write(data)
+	}
+
+	problems := checkProfiles(profiles)
+	for _, problem := range problems {
+		fmt.Fprintln(stderr, problem)
+	}
+	fmt.Fprintf(stderr, "Generated %d profiles, found %d problems\n", len(profiles), len(problems))
+	if len(problems) > 0 {
+		return 1
+	}
+	return 0
+}
+
+// dumpProfiles encodes the profiles with their protobuf JSON names, YAML is
+// converted from the JSON so both formats carry the same fields.
+func dumpProfiles(profiles []*pb.MatchProfile, format string) ([]byte, error) {
+	encoded := []json.RawMessage{}
+	for _, p := range profiles {
+		data, err := protojson.Marshal(p)
+		if err != nil {
+			return nil, fmt.Errorf("profile %s: %w", p.GetName(), err)
+		}
+		encoded = append(encoded, data)
+	}
+
+	data, err := json.MarshalIndent(encoded, "", "  ")
+	if err != nil || format == "json" {
+		return append(data, '\n'), err
+	}
+
+	var document interface{}
+	if err := json.Unmarshal(data, &document); err != nil {
+		return nil, err
+	}
+	return yaml.Marshal(document)
+}
+
+// checkProfiles reports duplicate names, profiles that compete for the same
+// tickets and filters Open Match would reject or that can never match.
+func checkProfiles(profiles []*pb.MatchProfile) []string {
+	problems := []string{}
+	names := map[string]int{}
+	filters := map[string]string{}
+	for index, p := range profiles {
+		name := p.GetName()
+		if name == "" {
+			problems = append(problems, fmt.Sprintf("profile %d: missing name", index))
+		} else if _, ok := names[name]; ok {
+			problems = append(problems, fmt.Sprintf("profile %s: duplicate name", name))
+		}
+		names[name] = index
+
+		if len(p.GetPools()) == 0 {
+			problems = append(problems, fmt.Sprintf("profile %s: no pools", name))
+		}
+		for _, pool := range p.GetPools() {
+			for _, problem := range checkPool(pool) {
+				problems = append(problems, fmt.Sprintf("profile %s pool %s: %s", name, pool.GetName(), problem))
+			}
+			key := poolKey(pool)
+			if other, ok := filters[key]; ok && other != name {
+				problems = append(problems, fmt.Sprintf("profile %s pool %s: same filters as profile %s", name, pool.GetName(), other))
+			}
+			filters[key] = name
+		}
+
+		settings, err := utils.GetMessage(p.GetExtensions(), utils.ProfileSettings)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("profile %s: %s", name, err.Error()))
+			continue
+		}
+		if settings.GetMaxPlayers() <= 0 {
+			problems = append(problems, fmt.Sprintf("profile %s: max players must be positive, got %d", name, settings.GetMaxPlayers()))
+		}
+		if err := utils.CheckStrategy(settings.GetStrategy(), settings.GetStrategyParams()); err != nil {
+			problems = append(problems, fmt.Sprintf("profile %s: %s", name, err.Error()))
+		}
+	}
+	return problems
+}
+
+func checkPool(pool *pb.Pool) []string {
+	problems := []string{}
+	if pool.GetName() == "" {
+		problems = append(problems, "missing pool name")
+	}
+	if len(pool.GetDoubleRangeFilters())+len(pool.GetStringEqualsFilters())+len(pool.GetTagPresentFilters()) == 0 {
+		problems = append(problems, "no filters, the pool matches every ticket")
+	}
+	for _, f := range pool.GetDoubleRangeFilters() {
+		if f.GetDoubleArg() == "" {
+			problems = append(problems, "double range filter without argument")
+		}
+		if f.GetMin() > f.GetMax() {
+			problems = append(problems, fmt.Sprintf("double range filter on %s has min %v above max %v", f.GetDoubleArg(), f.GetMin(), f.GetMax()))
+		}
+	}
+	for _, f := range pool.GetStringEqualsFilters() {
+		if f.GetStringArg() == "" {
+			problems = append(problems, "string equals filter without argument")
+		}
+	}
+	for _, f := range pool.GetTagPresentFilters() {
+		if f.GetTag() == "" {
+			problems = append(problems, "tag present filter without tag")
+		}
+	}
+	return problems
+}
+
+// poolKey is the same for pools that select the same tickets, independent of
+// the order of their filters.
+func poolKey(pool *pb.Pool) string {
+	parts := []string{}
+	for _, f := range pool.GetDoubleRangeFilters() {
+		parts = append(parts, fmt.Sprintf("range:%s:%v:%v", f.GetDoubleArg(), f.GetMin(), f.GetMax()))
+	}
+	for _, f := range pool.GetStringEqualsFilters() {
+		parts = append(parts, fmt.Sprintf("equals:%s:%s", f.GetStringArg(), f.GetValue()))
+	}
+	for _, f := range pool.GetTagPresentFilters() {
+		parts = append(parts, "tag:"+f.GetTag())
+	}
+	sort.Strings(parts)
+	return strings.Join(parts, ",")
+}
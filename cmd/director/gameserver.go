@@ -0,0 +1,153 @@
+package main
+
+import (
+	"context"
+	"log"
+	"math/rand"
+	"time"
+
+	"sim/internal/backfill"
+	"sim/internal/ticket"
+	simproto "sim/proto"
+
+	"google.golang.org/protobuf/proto"
+	"open-match.dev/open-match/pkg/pb"
+)
+
+// GameServerConfig controls the simulated game servers.
+type GameServerConfig struct {
+	GameDuration time.Duration
+	// AcknowledgeInterval is how often a server acknowledges its backfill and
+	// checks for players leaving.
+	AcknowledgeInterval time.Duration
+	// LeaveChance is the chance a player leaves within one interval.
+	LeaveChance float64
+}
+
+// GameServer simulates a game server that keeps its open slots on a backfill.
+// Open Match assigns the tickets of a backfill once the server acknowledges
+// it, and as players leave the server opens the slots up again. Without a pool
+// the server does not backfill.
+type GameServer struct {
+	fe         pb.FrontendServiceClient
+	config     GameServerConfig
+	allocation Allocation
+	allocator  GameServerAllocator
+	conn       string
+	pool       *pb.Pool
+	maxPlayers int
+	players    int
+	skill      float64
+	backfill   *pb.Backfill
+	rng        *rand.Rand
+}
+
+// NewGameServer creates a server for a game with players in it, b is the
+// backfill of the game if it started with open slots. The allocation is
+// released once the game ends.
+func NewGameServer(fe pb.FrontendServiceClient, config GameServerConfig, allocation Allocation, allocator GameServerAllocator, pool *pb.Pool, maxPlayers int, players int, skill float64, b *pb.Backfill, r *rand.Rand) *GameServer {
+	return &GameServer{
+		fe:         fe,
+		config:     config,
+		allocation: allocation,
+		allocator:  allocator,
+		conn:       allocation.Address,
+		pool:       pool,
+		maxPlayers: maxPlayers,
+		players:    players,
+		skill:      skill,
+		backfill:   b,
+		rng:        r,
+	}
+}
+
+// Run plays the game until it ends or the context is done, then deletes the
+// backfill of the server and releases it.
+func (s *GameServer) Run(ctx context.Context) {
+	defer s.allocator.Release(s.allocation)
+	end := time.NewTimer(s.config.GameDuration)
+	defer end.Stop()
+	ticker := time.NewTicker(s.config.AcknowledgeInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			s.close()
+			return
+		case <-end.C:
+			s.close()
+			log.Printf("Game on %s ended with %d players", s.conn, s.players)
+			return
+		case <-ticker.C:
+			if err := s.update(ctx); err != nil {
+				log.Printf("Failed to update backfill of game server %s, got %s", s.conn, err.Error())
+			}
+		}
+	}
+}
+
+func (s *GameServer) update(ctx context.Context) error {
+	if s.backfill != nil {
+		resp, err := s.fe.AcknowledgeBackfill(ctx, &pb.AcknowledgeBackfillRequest{
+			BackfillId: s.backfill.GetId(),
+			Assignment: newAssignment(s.conn),
+		})
+		if err != nil {
+			return err
+		}
+		s.backfill = resp.GetBackfill()
+		for _, t := range resp.GetTickets() {
+			s.players += ticket.GetPartySizeFromTicket(t)
+		}
+		if state, err := backfill.GetState(s.backfill); err == nil {
+			s.skill = state.GetSkill()
+		}
+	}
+
+	for player := s.players; player > 0; player-- {
+		if s.rng.Float64() < s.config.LeaveChance {
+			s.players--
+		}
+	}
+
+	state := &simproto.BackfillState{
+		MaxPlayers: int32(s.maxPlayers),
+		OpenSlots:  int32(s.maxPlayers - s.players),
+		Skill:      s.skill,
+	}
+	switch {
+	case s.pool == nil:
+	case state.OpenSlots > 0 && s.backfill == nil:
+		b, err := s.fe.CreateBackfill(ctx, &pb.CreateBackfillRequest{Backfill: backfill.New(s.pool, state)})
+		if err != nil {
+			return err
+		}
+		s.backfill = b
+		log.Printf("Game server %s opened backfill %s with %d open slots", s.conn, b.GetId(), state.OpenSlots)
+	case state.OpenSlots == 0 && s.backfill != nil:
+		s.close()
+	case s.backfill != nil:
+		current, err := backfill.GetState(s.backfill)
+		if err != nil || current.GetOpenSlots() != state.OpenSlots {
+			updated := proto.Clone(s.backfill).(*pb.Backfill)
+			backfill.SetState(updated, state)
+			b, err := s.fe.UpdateBackfill(ctx, &pb.UpdateBackfillRequest{Backfill: updated})
+			if err != nil {
+				return err
+			}
+			s.backfill = b
+		}
+	}
+	return nil
+}
+
+func (s *GameServer) close() {
+	if s.backfill == nil {
+		return
+	}
+	if _, err := s.fe.DeleteBackfill(context.Background(), &pb.DeleteBackfillRequest{BackfillId: s.backfill.GetId()}); err != nil {
+		log.Printf("Failed to delete backfill %s, got %s", s.backfill.GetId(), err.Error())
+	}
+	s.backfill = nil
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	utils "sim/internal"
+	"sim/internal/backfill"
+	"sim/internal/random"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"open-match.dev/open-match/pkg/pb"
+)
+
+// fakeFrontend keeps backfills in memory and hands the tickets queued in
+// joining to the server acknowledging the backfill.
+type fakeFrontend struct {
+	pb.FrontendServiceClient
+	backfills   map[string]*pb.Backfill
+	joining     map[string][]*pb.Ticket
+	connections []string
+	created     int
+	updated     int
+	deleted     []string
+}
+
+func newFakeFrontend() *fakeFrontend {
+	return &fakeFrontend{backfills: map[string]*pb.Backfill{}, joining: map[string][]*pb.Ticket{}}
+}
+
+func (f *fakeFrontend) CreateBackfill(ctx context.Context, req *pb.CreateBackfillRequest, opts ...grpc.CallOption) (*pb.Backfill, error) {
+	f.created++
+	b := proto.Clone(req.GetBackfill()).(*pb.Backfill)
+	b.Id = fmt.Sprintf("backfill-%d", f.created)
+	f.backfills[b.Id] = b
+	return b, nil
+}
+
+func (f *fakeFrontend) AcknowledgeBackfill(ctx context.Context, req *pb.AcknowledgeBackfillRequest, opts ...grpc.CallOption) (*pb.AcknowledgeBackfillResponse, error) {
+	b, ok := f.backfills[req.GetBackfillId()]
+	if !ok {
+		return nil, fmt.Errorf("backfill %s not found", req.GetBackfillId())
+	}
+	f.connections = append(f.connections, req.GetAssignment().GetConnection())
+	tickets := f.joining[b.Id]
+	delete(f.joining, b.Id)
+	return &pb.AcknowledgeBackfillResponse{Backfill: b, Tickets: tickets}, nil
+}
+
+func (f *fakeFrontend) UpdateBackfill(ctx context.Context, req *pb.UpdateBackfillRequest, opts ...grpc.CallOption) (*pb.Backfill, error) {
+	if _, ok := f.backfills[req.GetBackfill().GetId()]; !ok {
+		return nil, fmt.Errorf("backfill %s not found", req.GetBackfill().GetId())
+	}
+	f.updated++
+	b := proto.Clone(req.GetBackfill()).(*pb.Backfill)
+	f.backfills[b.Id] = b
+	return b, nil
+}
+
+func (f *fakeFrontend) DeleteBackfill(ctx context.Context, req *pb.DeleteBackfillRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	delete(f.backfills, req.GetBackfillId())
+	f.deleted = append(f.deleted, req.GetBackfillId())
+	return &emptypb.Empty{}, nil
+}
+
+func openSlots(t *testing.T, b *pb.Backfill) int32 {
+	state, err := backfill.GetState(b)
+	require.NoError(t, err)
+	return state.GetOpenSlots()
+}
+
+func TestGameServerBackfill(t *testing.T) {
+	require := require.New(t)
+	ctx := context.Background()
+
+	fe := newFakeFrontend()
+	fleet := NewFleet(FleetConfig{Regions: []string{"europe"}, Capacity: 1, WarmServers: 1}, random.New(1))
+	allocation, err := fleet.Allocate(ctx, "europe")
+	require.NoError(err)
+	pool := &pb.Pool{Name: utils.GPoolName, TagPresentFilters: []*pb.TagPresentFilter{{Tag: "europe"}}}
+	server := NewGameServer(fe, GameServerConfig{GameDuration: time.Hour, AcknowledgeInterval: time.Hour}, allocation, fleet, pool, 4, 2, 100, nil, random.New(1))
+
+	// A game that started with two players opens a backfill for the rest.
+	require.NoError(server.update(ctx))
+	require.Equal(1, fe.created)
+	require.Contains(fe.backfills, "backfill-1")
+	require.Equal(int32(2), openSlots(t, fe.backfills["backfill-1"]))
+
+	// A joining player is picked up on the next acknowledge and the open slots
+	// shrink.
+	fe.joining["backfill-1"] = []*pb.Ticket{testTicket("a")}
+	require.NoError(server.update(ctx))
+	require.Equal([]string{allocation.Address}, fe.connections)
+	require.Equal(3, server.players)
+	require.Equal(1, fe.updated)
+	require.Equal(int32(1), openSlots(t, fe.backfills["backfill-1"]))
+
+	// Nothing changed, the backfill is left alone.
+	require.NoError(server.update(ctx))
+	require.Equal(1, fe.updated)
+
+	// The full game closes its backfill.
+	fe.joining["backfill-1"] = []*pb.Ticket{testTicket("b")}
+	require.NoError(server.update(ctx))
+	require.Equal(4, server.players)
+	require.Equal([]string{"backfill-1"}, fe.deleted)
+	require.Empty(fe.backfills)
+
+	// Players leaving open it up again.
+	server.config.LeaveChance = 1
+	require.NoError(server.update(ctx))
+	require.Equal(0, server.players)
+	require.Equal(2, fe.created)
+	require.Equal(int32(4), openSlots(t, fe.backfills["backfill-2"]))
+
+	// Once the game ends the backfill is deleted and the server released.
+	require.Equal(0, fleet.Free("europe"))
+	ended, cancel := context.WithCancel(ctx)
+	cancel()
+	server.Run(ended)
+	require.Equal([]string{"backfill-1", "backfill-2"}, fe.deleted)
+	require.Empty(fe.backfills)
+	require.Equal(1, fleet.Free("europe"))
+}
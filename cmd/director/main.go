@@ -16,17 +16,26 @@ package main
 
 import (
 	"context"
+	"errors"
+	"flag"
 	"fmt"
 	"io"
 	"log"
 	"math/rand"
+	"os"
 	"sync"
 	"time"
 
 	utils "sim/internal"
+	"sim/internal/backfill"
 	grpccontext "sim/internal/grpc"
+	"sim/internal/random"
+	"sim/internal/ticket"
+	simproto "sim/proto"
 
 	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
 	"open-match.dev/open-match/pkg/pb"
 
 	"github.com/sirupsen/logrus"
@@ -38,7 +47,7 @@ var logger = logrus.WithFields(logrus.Fields{
 })
 
 // The Director in this tutorial continously polls Open Match for the Match
-// Profiles and makes random assignments for the Tickets in the returned matches.
+// Profiles and assigns game servers of a simulated fleet to the returned matches.
 
 const (
 	// The endpoint for the Open Match Backend service.
@@ -48,11 +57,54 @@ const (
 	functionPort     = 50502
 	// The endpoint for the Open Match Frontend service.
 	omFrontendEndpoint = "open-match-frontend.open-match.svc.cluster.local:50504"
+	// The pendingReleaseTimeout of the Open Match config in config.yaml.
+	omPendingReleaseTimeout = time.Minute
 )
 
 func main() {
+	// "director profiles" checks or dumps the profiles without running.
+	if len(os.Args) > 1 && os.Args[1] == "profiles" {
+		os.Exit(runProfiles(os.Args[2:], os.Stdout, os.Stderr))
+	}
+
+	seedFlag := flag.Int64("seed", 0, "seed of the server assignment, zero picks one from the clock")
+	servers := GameServerConfig{}
+	flag.DurationVar(&servers.GameDuration, "game-duration", 10*time.Minute, "length of a simulated game")
+	flag.DurationVar(&servers.AcknowledgeInterval, "acknowledge-interval", 5*time.Second, "how often game servers acknowledge their backfill")
+	flag.Float64Var(&servers.LeaveChance, "leave-chance", 0.02, "chance a player leaves a game within one acknowledge interval")
+	fleetConfig := FleetConfig{}
+	flag.IntVar(&fleetConfig.Capacity, "fleet-capacity", 200, "number of game servers in every region")
+	flag.IntVar(&fleetConfig.WarmServers, "fleet-warm", 20, "number of free game servers every region keeps running")
+	flag.DurationVar(&fleetConfig.WarmLatency, "warm-latency", 100*time.Millisecond, "time to allocate a running game server")
+	flag.DurationVar(&fleetConfig.ColdLatency, "cold-latency", 5*time.Second, "time to allocate a game server that has to start")
+	flag.Float64Var(&fleetConfig.FailureChance, "allocation-failure-chance", 0.01, "chance a game server allocation fails")
+	queueTimeout := flag.Duration("allocation-queue-timeout", 30*time.Second, "how long a match waits for a game server before its tickets are requeued, has to stay below the pending release timeout of Open Match")
+	scenarioPath := flag.String("scenario", "", "YAML or JSON file with the regions, modes and lobbies to generate profiles for")
+	flag.Parse()
+
 	log.Printf("Starting Director")
 
+	// Open Match hands the tickets of a match to other proposals once the
+	// pending release timeout passes, so they have to be assigned before.
+	if *queueTimeout >= omPendingReleaseTimeout {
+		log.Fatalf("Invalid allocation queue timeout, got %s which is not below the pending release timeout %s", *queueTimeout, omPendingReleaseTimeout)
+	}
+
+	scenarioConfig, err := loadScenario(*scenarioPath)
+	if err != nil {
+		log.Fatalf("Failed to load scenario, got %s", err.Error())
+	}
+	if *scenarioPath != "" {
+		log.Printf("Loaded scenario from %s", *scenarioPath)
+	}
+	finals := newScenario(scenarioConfig)
+
+	seed := random.NewSeed(*seedFlag)
+	log.Printf("Running with seed %d", seed)
+	// Profiles are fetched concurrently, so the source has to be safe for
+	// concurrent use.
+	rng := random.NewLocked(seed)
+
 	// Connect to Open Match Backend.
 	conn, err := grpc.Dial(omBackendEndpoint, grpccontext.NewGRPCDialOptions(logger)...)
 	if err != nil {
@@ -72,30 +124,23 @@ func main() {
 	defer conn2.Close()
 	fe := pb.NewFrontendServiceClient(conn2)
 
-	scenario := &FinalsGameScenario{
-		modeData:        []GameModeData{},
-		regions:         utils.GRegions,
-		activePasswords: []string{utils.GPasswordArg},
-	}
-
-	for _, mode := range utils.GameModes {
-		modeData := GameModeData{
-			modeName:           mode,
-			skillBoundaries:    []float64{0, 500, 1500},
-			maxSkillDifference: float64(utils.GMaxSkill),
-			trustedQueues:      true,
-			playersPerGame:     16,
-			skillDiffBand:      50,
-			backfill:           true,
-		}
-		scenario.modeData = append(scenario.modeData, modeData)
+	fleetConfig.Regions = finals.regions
+	assigner := &matchAssigner{
+		be:           be,
+		fe:           fe,
+		allocator:    NewFleet(fleetConfig, random.Derive(rng)),
+		servers:      servers,
+		queueTimeout: *queueTimeout,
+		rng:          rng,
 	}
 
-	profiles := profilesCall(scenario)
+	profiles := profilesCall(finals)
 	log.Printf("Fetching matches for %v profiles", len(profiles))
 
 	for range time.Tick(time.Second * 5) {
-		// Fetch matches for each profile and make random assignments for Tickets in
+		assigner.retryQueued()
+
+		// Fetch matches for each profile and assign game servers to the Tickets in
 		// the matches returned.
 		var wg sync.WaitGroup
 		for _, p := range profiles {
@@ -116,10 +161,7 @@ func main() {
 				if count > 0 {
 					log.Printf("Generated %d matches for profile %s amount of tickets %d", len(matches), p.Name, count)
 				}
-				if err := assign(be, matches, p, fe); err != nil {
-					log.Printf("Failed to assign servers to matches, got %s", err.Error())
-					return
-				}
+				assigner.assign(matches, p)
 			}(&wg, p)
 		}
 
@@ -164,29 +206,206 @@ func fetch(be pb.BackendServiceClient, p *pb.MatchProfile) ([]*pb.Match, error)
 	return result, nil
 }
 
-func assign(be pb.BackendServiceClient, matches []*pb.Match, matchProfile *pb.MatchProfile, fe pb.FrontendServiceClient) error {
+// queuedMatch is a match waiting for a game server.
+type queuedMatch struct {
+	match    *pb.Match
+	profile  *pb.MatchProfile
+	queuedAt time.Time
+	attempts int
+}
+
+// matchAssigner allocates game servers for matches and assigns their tickets.
+// Servers are allocated in the background, so a slow allocation holds up
+// neither the other matches nor the next fetch. Matches that do not get a
+// server are queued and retried, once they waited longer than queueTimeout
+// their tickets are released back to Open Match.
+type matchAssigner struct {
+	be           pb.BackendServiceClient
+	fe           pb.FrontendServiceClient
+	allocator    GameServerAllocator
+	servers      GameServerConfig
+	queueTimeout time.Duration
+	rng          *rand.Rand
+
+	mu          sync.Mutex
+	queue       []queuedMatch
+	allocations sync.WaitGroup
+}
+
+// assign starts allocating game servers for the matches.
+func (a *matchAssigner) assign(matches []*pb.Match, matchProfile *pb.MatchProfile) {
 	for _, match := range matches {
-		ticketIDs := []string{}
-		for _, t := range match.GetTickets() {
-			ticketIDs = append(ticketIDs, t.Id)
-		}
+		a.try(queuedMatch{match: match, profile: matchProfile, queuedAt: time.Now()})
+	}
+}
 
-		conn := fmt.Sprintf("%d.%d.%d.%d:2222", rand.Intn(256), rand.Intn(256), rand.Intn(256), rand.Intn(256))
-		req := &pb.AssignTicketsRequest{
-			Assignments: []*pb.AssignmentGroup{
-				{
-					TicketIds: ticketIDs,
-					Assignment: &pb.Assignment{
-						Connection: conn,
-					},
-				},
-			},
+// retryQueued starts allocating game servers for the queued matches again.
+func (a *matchAssigner) retryQueued() {
+	a.mu.Lock()
+	queue := a.queue
+	a.queue = nil
+	a.mu.Unlock()
+
+	for _, queued := range queue {
+		a.try(queued)
+	}
+}
+
+// try starts the match in the background. The allocation has to finish
+// within the queue timeout, matches that can not be started then are given
+// back to Open Match.
+func (a *matchAssigner) try(queued queuedMatch) {
+	queued.attempts++
+	a.allocations.Add(1)
+	go func() {
+		defer a.allocations.Done()
+		ctx, cancel := context.WithDeadline(context.Background(), queued.queuedAt.Add(a.queueTimeout))
+		defer cancel()
+
+		err := a.start(ctx, queued.match, queued.profile)
+		switch {
+		case err == nil:
+		case retryable(err) && time.Since(queued.queuedAt) < a.queueTimeout:
+			if queued.attempts == 1 {
+				log.Printf("Queueing match %s of profile %s, got %s", queued.match.GetMatchId(), queued.profile.GetName(), err.Error())
+			}
+			a.mu.Lock()
+			a.queue = append(a.queue, queued)
+			a.mu.Unlock()
+		default:
+			log.Printf("Requeueing match %s of profile %s, got %s", queued.match.GetMatchId(), queued.profile.GetName(), err.Error())
+			if err := a.release(queued.match); err != nil {
+				log.Printf("Failed to requeue match %s, got %s", queued.match.GetMatchId(), err.Error())
+			}
 		}
+	}()
+}
+
+// wait blocks until the allocations that were started are done.
+func (a *matchAssigner) wait() {
+	a.allocations.Wait()
+}
+
+func retryable(err error) bool {
+	return errors.Is(err, ErrNoCapacity) || errors.Is(err, ErrAllocationFailed)
+}
 
-		if _, err := be.AssignTickets(context.Background(), req); err != nil {
-			return fmt.Errorf("AssignTickets failed for match %v, got %w", match.GetMatchId(), err)
+// release gives the tickets of a match that did not start back to Open Match
+// so that they are matched again.
+func (a *matchAssigner) release(match *pb.Match) error {
+	if match.GetBackfill() != nil {
+		// Deleting the backfill releases its tickets as well.
+		_, err := a.fe.DeleteBackfill(context.Background(), &pb.DeleteBackfillRequest{BackfillId: match.GetBackfill().GetId()})
+		return err
+	}
+
+	ticketIDs := []string{}
+	for _, t := range match.GetTickets() {
+		ticketIDs = append(ticketIDs, t.Id)
+	}
+	_, err := a.be.ReleaseTickets(context.Background(), &pb.ReleaseTicketsRequest{TicketIds: ticketIDs})
+	return err
+}
+
+// start allocates a game server for the match and assigns its tickets, ctx
+// bounds the allocation.
+func (a *matchAssigner) start(ctx context.Context, match *pb.Match, matchProfile *pb.MatchProfile) error {
+	settings, err := utils.GetMessage(matchProfile.GetExtensions(), utils.ProfileSettings)
+	if err != nil {
+		return err
+	}
+
+	// The tickets of a backfill match are assigned once its game server
+	// acknowledges the backfill, only new games need a server.
+	if match.GetBackfill() != nil {
+		if !match.GetAllocateGameserver() {
+			return nil
+		}
+		state, err := backfill.GetState(match.GetBackfill())
+		if err != nil {
+			return err
 		}
+		allocation, err := a.allocator.Allocate(ctx, settings.GetRegion())
+		if err != nil {
+			return err
+		}
+		server := NewGameServer(a.fe, a.servers, allocation, a.allocator, matchProfile.GetPools()[0], int(state.GetMaxPlayers()),
+			0, state.GetSkill(), match.GetBackfill(), random.Derive(a.rng))
+		go server.Run(context.Background())
+		return nil
+	}
+
+	allocation, err := a.allocator.Allocate(ctx, settings.GetRegion())
+	if err != nil {
+		return err
+	}
+
+	ticketIDs := []string{}
+	for _, t := range match.GetTickets() {
+		ticketIDs = append(ticketIDs, t.Id)
 	}
 
+	conn := allocation.Address
+	req := &pb.AssignTicketsRequest{
+		Assignments: []*pb.AssignmentGroup{
+			{
+				TicketIds:  ticketIDs,
+				Assignment: newAssignment(conn),
+			},
+		},
+	}
+
+	// Team matches get one assignment per team so the game server knows
+	// where to place every ticket.
+	if layout, err := utils.GetMessage(match.GetExtensions(), utils.MatchTeams); err == nil {
+		req.Assignments = teamAssignments(layout, conn)
+	}
+
+	if _, err := a.be.AssignTickets(context.Background(), req); err != nil {
+		a.allocator.Release(allocation)
+		return fmt.Errorf("AssignTickets failed for match %v, got %w", match.GetMatchId(), err)
+	}
+
+	// The server is released when the game ends, full games open backfills
+	// as players leave.
+	skill, players := 0.0, 0
+	for _, t := range match.GetTickets() {
+		size := ticket.GetPartySizeFromTicket(t)
+		skill += ticket.GetSkillFromTicket(t) * float64(size)
+		players += size
+	}
+	if players > 0 {
+		skill /= float64(players)
+	}
+	var pool *pb.Pool
+	if settings.GetBackfill() && len(matchProfile.GetPools()) > 0 {
+		pool = matchProfile.GetPools()[0]
+	}
+	server := NewGameServer(a.fe, a.servers, allocation, a.allocator, pool, int(settings.GetMaxPlayers()), players, skill, nil, random.Derive(a.rng))
+	go server.Run(context.Background())
 	return nil
 }
+
+// newAssignment creates an assignment to the server stamped with the time of
+// the assignment.
+func newAssignment(conn string) *pb.Assignment {
+	assignment := &pb.Assignment{
+		Connection: conn,
+		Extensions: make(map[string]*anypb.Any),
+	}
+	utils.SetMessage(assignment.Extensions, utils.AssignmentTime, timestamppb.Now())
+	return assignment
+}
+
+func teamAssignments(layout *simproto.TeamLayout, conn string) []*pb.AssignmentGroup {
+	groups := []*pb.AssignmentGroup{}
+	for _, team := range layout.GetTeams() {
+		assignment := newAssignment(conn)
+		utils.SetMessage(assignment.Extensions, utils.AssignmentTeam, team)
+		groups = append(groups, &pb.AssignmentGroup{
+			TicketIds:  team.GetTicketIds(),
+			Assignment: assignment,
+		})
+	}
+	return groups
+}
@@ -15,11 +15,14 @@
 package main
 
 import (
-	"fmt"
+	"time"
 
 	utils "sim/internal"
+	"sim/internal/scenario"
+	simproto "sim/proto"
 
 	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/durationpb"
 	"open-match.dev/open-match/pkg/pb"
 )
 
@@ -29,9 +32,21 @@ type GameModeData struct {
 	maxSkillDifference float64
 	trustedQueues      bool
 	playersPerGame     int
+	teams              int
 	skillDiffBand      int
 	backfill           bool
+	backfillMinPlayers int
+	backfillMinWait    time.Duration
 	beginner           bool
+	// Matchmaking strategy of the match function and its parameters.
+	strategy       string
+	strategyParams map[string]string
+	// skillCurve widens skillDiffBand for tickets that have been waiting.
+	skillCurve []*simproto.SkillWindowPoint
+	// Highest ping towards the region of a profile and the largest ping
+	// difference within a match, zero does not limit them.
+	maxPing       float64
+	maxPingSpread float64
 }
 
 // TeamShooterScenario provides the required methods for running a scenario.
@@ -39,6 +54,7 @@ type FinalsGameScenario struct {
 	modeData        []GameModeData
 	regions         []string
 	activePasswords []string
+	passwordPlayers int
 	maxLatency      float64
 }
 
@@ -76,7 +92,7 @@ func profilesCall(t *FinalsGameScenario) []*pb.MatchProfile {
 						}
 
 						matchProfile := &pb.MatchProfile{
-							Name: fmt.Sprintf("%s_%s", region, mode),
+							Name: scenario.ProfileName(region, mode.modeName, mode.skillBoundaries[i], mode.skillBoundaries[i+1], trustedIndex > 0, beginnerIndex > 0),
 							Pools: []*pb.Pool{
 								{
 									Name: poolName,
@@ -102,9 +118,20 @@ func profilesCall(t *FinalsGameScenario) []*pb.MatchProfile {
 							},
 							Extensions: make(map[string]*anypb.Any),
 						}
-						utils.AddExtensionFloat64(matchProfile.Extensions, utils.GMaxPlayersKey, float64(mode.playersPerGame))
-						utils.AddExtensionFloat64(matchProfile.Extensions, utils.GMaxSkillDifference, float64(mode.skillDiffBand))
-						utils.AddExtensionString(matchProfile.Extensions, utils.GProfileRegion, region)
+						utils.SetMessage(matchProfile.Extensions, utils.ProfileSettings, &simproto.ProfileSettings{
+							Region:             region,
+							MaxPlayers:         int32(mode.playersPerGame),
+							MaxSkillDifference: float64(mode.skillDiffBand),
+							NumTeams:           int32(mode.teams),
+							Strategy:           mode.strategy,
+							StrategyParams:     mode.strategyParams,
+							SkillCurve:         mode.skillCurve,
+							MaxPing:            mode.maxPing,
+							MaxPingSpread:      mode.maxPingSpread,
+							Backfill:           mode.backfill,
+							BackfillMinPlayers: int32(mode.backfillMinPlayers),
+							BackfillMinWait:    durationpb.New(mode.backfillMinWait),
+						})
 						if beginnerIndex > 0 {
 							filter := []*pb.TagPresentFilter{
 								{
@@ -123,8 +150,8 @@ func profilesCall(t *FinalsGameScenario) []*pb.MatchProfile {
 
 	if t.activePasswords != nil {
 		for _, password := range t.activePasswords {
-			p = append(p, &pb.MatchProfile{
-				Name: fmt.Sprintf("password_%s", password),
+			matchProfile := &pb.MatchProfile{
+				Name: scenario.PasswordProfileName(password),
 				Pools: []*pb.Pool{
 					{
 						Name: poolName,
@@ -135,7 +162,13 @@ func profilesCall(t *FinalsGameScenario) []*pb.MatchProfile {
 						},
 					},
 				},
+				Extensions: make(map[string]*anypb.Any),
+			}
+			utils.SetMessage(matchProfile.Extensions, utils.ProfileSettings, &simproto.ProfileSettings{
+				MaxPlayers: int32(t.passwordPlayers),
+				Strategy:   "fifo",
 			})
+			p = append(p, matchProfile)
 		}
 	}
 
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"sim/internal/scenario"
+
+	"github.com/stretchr/testify/require"
+	"open-match.dev/open-match/pkg/pb"
+)
+
+func TestProfileNames(t *testing.T) {
+	config := scenario.Default()
+	config.Modes[0].BeginnerSplit = true
+	profiles := profilesCall(newScenario(config))
+	require.Empty(t, checkProfiles(profiles))
+
+	// Generating twice gives the same names in the same order.
+	again := profilesCall(newScenario(config))
+	for i := range profiles {
+		require.Equal(t, profiles[i].GetName(), again[i].GetName())
+	}
+}
+
+func TestCheckProfiles(t *testing.T) {
+	profiles := profilesCall(newScenario(scenario.Default()))
+	duplicate := profilesCall(newScenario(scenario.Default()))[0]
+	copied := profilesCall(newScenario(scenario.Default()))[0]
+	copied.Name = "copy"
+	broken := profilesCall(newScenario(scenario.Default()))[1]
+	broken.Name = "broken"
+	broken.Pools[0].DoubleRangeFilters[0].Min = 1000
+	broken.Pools[0].TagPresentFilters = append(broken.Pools[0].TagPresentFilters, &pb.TagPresentFilter{})
+
+	problems := checkProfiles(append(profiles, duplicate, copied, broken))
+	require.Contains(t, problems, "profile europe.bank_it.skill_0-500.untrusted.open: duplicate name")
+	require.Contains(t, problems, "profile copy pool all: same filters as profile europe.bank_it.skill_0-500.untrusted.open")
+	require.Contains(t, problems, "profile broken pool all: double range filter on skill has min 1000 above max 525")
+	require.Contains(t, problems, "profile broken pool all: tag present filter without tag")
+	require.Len(t, problems, 4)
+}
+
+func TestRunProfilesDump(t *testing.T) {
+	stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+	require.Equal(t, 0, runProfiles([]string{"--dump"}, stdout, stderr))
+
+	dumped := []map[string]interface{}{}
+	require.NoError(t, json.Unmarshal(stdout.Bytes(), &dumped))
+	require.Len(t, dumped, len(profilesCall(newScenario(scenario.Default()))))
+	require.Equal(t, "europe.bank_it.skill_0-500.untrusted.open", dumped[0]["name"])
+	require.Contains(t, stderr.String(), "found 0 problems")
+
+	stdout.Reset()
+	require.Equal(t, 0, runProfiles([]string{"--dump", "--format", "yaml"}, stdout, stderr))
+	require.Contains(t, stdout.String(), "name: password.password")
+
+	require.Equal(t, 2, runProfiles([]string{"--format", "xml"}, stdout, stderr))
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"sim/internal/scenario"
+	simproto "sim/proto"
+
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// loadScenario reads and validates the scenario file at path, an empty path
+// returns the built in scenario.
+func loadScenario(path string) (scenario.Config, error) {
+	if path == "" {
+		return scenario.Default(), nil
+	}
+	return scenario.Load(path)
+}
+
+// newScenario converts the configuration into the scenario the profiles are
+// generated from.
+func newScenario(c scenario.Config) *FinalsGameScenario {
+	finals := &FinalsGameScenario{
+		modeData:        []GameModeData{},
+		regions:         c.Regions,
+		activePasswords: c.Passwords.Lobbies,
+		passwordPlayers: c.Passwords.Players,
+	}
+
+	for _, mode := range c.Modes {
+		skillCurve := []*simproto.SkillWindowPoint{}
+		for _, point := range mode.SkillCurve {
+			skillCurve = append(skillCurve, &simproto.SkillWindowPoint{
+				Wait:               durationpb.New(point.Wait),
+				MaxSkillDifference: point.MaxSkillDifference,
+			})
+		}
+		finals.modeData = append(finals.modeData, GameModeData{
+			modeName:           mode.Name,
+			skillBoundaries:    mode.SkillBuckets,
+			maxSkillDifference: mode.BucketOverlap,
+			trustedQueues:      mode.TrustedSplit,
+			playersPerGame:     mode.PlayersPerGame,
+			teams:              mode.Teams,
+			skillDiffBand:      mode.SkillWindow,
+			backfill:           mode.Backfill,
+			backfillMinPlayers: mode.BackfillMinPlayers,
+			backfillMinWait:    mode.BackfillMinWait,
+			beginner:           mode.BeginnerSplit,
+			strategy:           mode.Strategy,
+			strategyParams:     mode.StrategyParams,
+			skillCurve:         skillCurve,
+			maxPing:            mode.MaxPing,
+			maxPingSpread:      mode.MaxPingSpread,
+		})
+	}
+	return finals
+}
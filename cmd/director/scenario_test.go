@@ -0,0 +1,28 @@
+package main
+
+import (
+	"testing"
+
+	"sim/internal/scenario"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDefaultScenarioFile(t *testing.T) {
+	config, err := loadScenario("scenario.yaml")
+	require.NoError(t, err)
+	require.Equal(t, scenario.Default(), config)
+	require.Len(t, profilesCall(newScenario(config)), 2*4*2*2+1)
+}
+
+func TestParseScenarioJSON(t *testing.T) {
+	config, err := scenario.Parse([]byte(`{
+		"regions": ["asia"],
+		"modes": [{"name": "duel", "players_per_game": 2, "skill_buckets": [0, 3000], "beginner_split": true, "strategy": "fifo"}]
+	}`))
+	require.NoError(t, err)
+
+	profiles := profilesCall(newScenario(config))
+	require.Len(t, profiles, 2)
+	require.Equal(t, "asia", profiles[0].GetPools()[0].GetTagPresentFilters()[0].GetTag())
+}
@@ -0,0 +1,194 @@
+package evaluator
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	utils "sim/internal"
+
+	"open-match.dev/open-match/pkg/pb"
+)
+
+// Policy decides which of two overlapping proposals is accepted.
+type Policy string
+
+const (
+	// PolicyScore prefers the proposal with the highest evaluation score.
+	PolicyScore Policy = "score"
+	// PolicyOldest prefers the proposal whose longest waiting ticket has waited
+	// the longest.
+	PolicyOldest Policy = "oldest"
+)
+
+func ParsePolicy(name string) (Policy, error) {
+	switch policy := Policy(name); policy {
+	case PolicyScore, PolicyOldest:
+		return policy, nil
+	}
+	return "", fmt.Errorf("unknown policy %q, expected score or oldest", name)
+}
+
+// Config of the evaluator.
+type Config struct {
+	Policy Policy
+	// DefaultQuota is the most matches a profile gets accepted in one
+	// evaluation, zero does not limit it. Quotas overrides it per profile.
+	DefaultQuota int
+	Quotas       map[string]int
+}
+
+// ParseQuotas parses comma separated profile=quota pairs.
+func ParseQuotas(spec string) (map[string]int, error) {
+	quotas := map[string]int{}
+	if strings.TrimSpace(spec) == "" {
+		return quotas, nil
+	}
+
+	for _, entry := range strings.Split(spec, ",") {
+		profile, value, ok := strings.Cut(strings.TrimSpace(entry), "=")
+		if !ok {
+			return nil, fmt.Errorf("expected profile=quota, got %q", entry)
+		}
+		quota, err := strconv.Atoi(value)
+		if err != nil || quota < 0 {
+			return nil, fmt.Errorf("invalid quota in %q", entry)
+		}
+		quotas[profile] = quota
+	}
+	return quotas, nil
+}
+
+func (c Config) quota(profile string) int {
+	if quota, ok := c.Quotas[profile]; ok {
+		return quota
+	}
+	return c.DefaultQuota
+}
+
+// EvaluatorService implements pb.EvaluatorServer.
+type EvaluatorService struct {
+	config Config
+}
+
+func NewEvaluatorService(config Config) *EvaluatorService {
+	return &EvaluatorService{config: config}
+}
+
+// Evaluate reads every proposal of the cycle and streams back the IDs of the
+// matches that are accepted.
+func (s *EvaluatorService) Evaluate(stream pb.Evaluator_EvaluateServer) error {
+	proposals := []*pb.Match{}
+	for {
+		req, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			log.Printf("Failed to receive proposals, got %s", err.Error())
+			return err
+		}
+		proposals = append(proposals, req.GetMatch())
+	}
+
+	accepted := evaluate(proposals, s.config, time.Now())
+	log.Printf("Accepted %d of %d proposals", len(accepted), len(proposals))
+	for _, id := range accepted {
+		if err := stream.Send(&pb.EvaluateResponse{MatchId: id}); err != nil {
+			log.Printf("Failed to stream accepted matches, got %s", err.Error())
+			return err
+		}
+	}
+	return nil
+}
+
+// evaluate accepts proposals in the order of the policy, skipping those that
+// share a ticket or backfill with an accepted one or whose profile has used up
+// its quota.
+func evaluate(proposals []*pb.Match, config Config, now time.Time) []string {
+	keys := make(map[*pb.Match]float64, len(proposals))
+	for _, proposal := range proposals {
+		keys[proposal] = priority(proposal, config.Policy, now)
+	}
+	sort.SliceStable(proposals, func(i, j int) bool {
+		if keys[proposals[i]] != keys[proposals[j]] {
+			return keys[proposals[i]] > keys[proposals[j]]
+		}
+		return proposals[i].GetMatchId() < proposals[j].GetMatchId()
+	})
+
+	usedTickets := map[string]bool{}
+	usedBackfills := map[string]bool{}
+	perProfile := map[string]int{}
+	accepted := []string{}
+	for _, proposal := range proposals {
+		profile := proposal.GetMatchProfile()
+		if quota := config.quota(profile); quota > 0 && perProfile[profile] >= quota {
+			continue
+		}
+		if id := proposal.GetBackfill().GetId(); id != "" && usedBackfills[id] {
+			continue
+		}
+		overlaps := false
+		for _, t := range proposal.GetTickets() {
+			if usedTickets[t.GetId()] {
+				overlaps = true
+				break
+			}
+		}
+		if overlaps {
+			continue
+		}
+
+		for _, t := range proposal.GetTickets() {
+			usedTickets[t.GetId()] = true
+		}
+		if id := proposal.GetBackfill().GetId(); id != "" {
+			usedBackfills[id] = true
+		}
+		perProfile[profile]++
+		accepted = append(accepted, proposal.GetMatchId())
+	}
+	return accepted
+}
+
+// priority returns the value the policy orders proposals by, higher first.
+func priority(proposal *pb.Match, policy Policy, now time.Time) float64 {
+	if policy == PolicyOldest {
+		return oldestWait(proposal, now).Seconds()
+	}
+
+	criteria := &pb.DefaultEvaluationCriteria{}
+	input, ok := proposal.GetExtensions()[utils.GEvaluationInputKey]
+	if !ok {
+		return 0
+	}
+	if err := input.UnmarshalTo(criteria); err != nil {
+		log.Printf("Failed to read evaluation input of match %s, got %s", proposal.GetMatchId(), err.Error())
+		return 0
+	}
+	return criteria.GetScore()
+}
+
+// oldestWait returns the longest wait of the match, from its quality if the
+// match function set one and from the ticket creation times otherwise.
+func oldestWait(proposal *pb.Match, now time.Time) time.Duration {
+	if quality, err := utils.GetMessage(proposal.GetExtensions(), utils.MatchQuality); err == nil && quality.GetMaxWait() != nil {
+		return quality.GetMaxWait().AsDuration()
+	}
+
+	longest := time.Duration(0)
+	for _, t := range proposal.GetTickets() {
+		if t.GetCreateTime() == nil {
+			continue
+		}
+		if wait := now.Sub(t.GetCreateTime().AsTime()); wait > longest {
+			longest = wait
+		}
+	}
+	return longest
+}
@@ -0,0 +1,132 @@
+package evaluator
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	utils "sim/internal"
+	simproto "sim/proto"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"open-match.dev/open-match/pkg/pb"
+)
+
+// memoryStream is an in-memory pb.Evaluator_EvaluateServer.
+type memoryStream struct {
+	grpc.ServerStream
+	requests  []*pb.EvaluateRequest
+	responses []string
+}
+
+func (s *memoryStream) Context() context.Context {
+	return context.Background()
+}
+
+func (s *memoryStream) Recv() (*pb.EvaluateRequest, error) {
+	if len(s.requests) == 0 {
+		return nil, io.EOF
+	}
+	req := s.requests[0]
+	s.requests = s.requests[1:]
+	return req, nil
+}
+
+func (s *memoryStream) Send(resp *pb.EvaluateResponse) error {
+	s.responses = append(s.responses, resp.GetMatchId())
+	return nil
+}
+
+func proposal(id, profile string, score float64, wait time.Duration, ticketIDs ...string) *pb.Match {
+	match := &pb.Match{
+		MatchId:      id,
+		MatchProfile: profile,
+		Extensions:   map[string]*anypb.Any{},
+	}
+	for _, ticketID := range ticketIDs {
+		match.Tickets = append(match.Tickets, &pb.Ticket{Id: ticketID})
+	}
+	match.Extensions[utils.GEvaluationInputKey] = utils.MustAny(&pb.DefaultEvaluationCriteria{Score: score})
+	utils.SetMessage(match.Extensions, utils.MatchQuality, &simproto.MatchQuality{MaxWait: durationpb.New(wait)})
+	return match
+}
+
+func evaluateStream(t *testing.T, config Config, proposals ...*pb.Match) []string {
+	stream := &memoryStream{}
+	for _, p := range proposals {
+		stream.requests = append(stream.requests, &pb.EvaluateRequest{Match: p})
+	}
+	require.NoError(t, NewEvaluatorService(config).Evaluate(stream))
+	return stream.responses
+}
+
+func TestScorePolicy(t *testing.T) {
+	accepted := evaluateStream(t, Config{Policy: PolicyScore},
+		proposal("low", "a", 1, time.Minute, "t1", "t2"),
+		proposal("high", "b", 5, time.Second, "t2", "t3"),
+		proposal("apart", "a", 0, 0, "t4"),
+	)
+	require.Equal(t, []string{"high", "apart"}, accepted)
+}
+
+func TestOldestPolicy(t *testing.T) {
+	accepted := evaluateStream(t, Config{Policy: PolicyOldest},
+		proposal("low", "a", 1, time.Minute, "t1", "t2"),
+		proposal("high", "b", 5, time.Second, "t2", "t3"),
+	)
+	require.Equal(t, []string{"low"}, accepted)
+
+	// Without a quality the oldest ticket decides.
+	now := time.Now()
+	older := &pb.Match{MatchId: "older", Tickets: []*pb.Ticket{{Id: "t1", CreateTime: timestamppb.New(now.Add(-time.Minute))}}}
+	newer := &pb.Match{MatchId: "newer", Tickets: []*pb.Ticket{{Id: "t1", CreateTime: timestamppb.New(now.Add(-time.Second))}}}
+	require.Equal(t, []string{"older"}, evaluate([]*pb.Match{newer, older}, Config{Policy: PolicyOldest}, now))
+}
+
+func TestBackfillOverlap(t *testing.T) {
+	first := proposal("first", "a", 2, 0, "t1")
+	first.Backfill = &pb.Backfill{Id: "b1"}
+	second := proposal("second", "a", 1, 0, "t2")
+	second.Backfill = &pb.Backfill{Id: "b1"}
+
+	require.Equal(t, []string{"first"}, evaluateStream(t, Config{Policy: PolicyScore}, first, second))
+}
+
+func TestQuotas(t *testing.T) {
+	proposals := func() []*pb.Match {
+		return []*pb.Match{
+			proposal("a1", "a", 9, 0, "t1"),
+			proposal("a2", "a", 8, 0, "t2"),
+			proposal("a3", "a", 7, 0, "t3"),
+			proposal("b1", "b", 1, 0, "t4"),
+			proposal("b2", "b", 0, 0, "t5"),
+		}
+	}
+
+	accepted := evaluateStream(t, Config{Policy: PolicyScore, DefaultQuota: 1}, proposals()...)
+	require.Equal(t, []string{"a1", "b1"}, accepted)
+
+	accepted = evaluateStream(t, Config{Policy: PolicyScore, DefaultQuota: 1, Quotas: map[string]int{"a": 2, "b": 0}}, proposals()...)
+	require.Equal(t, []string{"a1", "a2", "b1", "b2"}, accepted)
+}
+
+func TestParse(t *testing.T) {
+	policy, err := ParsePolicy("oldest")
+	require.NoError(t, err)
+	require.Equal(t, PolicyOldest, policy)
+	_, err = ParsePolicy("random")
+	require.Error(t, err)
+
+	quotas, err := ParseQuotas("a=1, b=3")
+	require.NoError(t, err)
+	require.Equal(t, map[string]int{"a": 1, "b": 3}, quotas)
+	_, err = ParseQuotas("a")
+	require.Error(t, err)
+	_, err = ParseQuotas("a=-1")
+	require.Error(t, err)
+}
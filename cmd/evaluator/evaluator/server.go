@@ -0,0 +1,36 @@
+package evaluator
+
+import (
+	"fmt"
+	"log"
+	"net"
+
+	grpccontext "sim/internal/grpc"
+
+	"google.golang.org/grpc"
+	"open-match.dev/open-match/pkg/pb"
+
+	"github.com/sirupsen/logrus"
+)
+
+var logger = logrus.WithFields(logrus.Fields{
+	"app":       "openmatch",
+	"component": "scale.evaluator",
+})
+
+// Start creates and starts the evaluator server. Open Match calls it with the
+// proposals of every cycle.
+func Start(serverPort int, config Config) {
+	server := grpc.NewServer(grpccontext.NewGRPCServerOptions(logger)...)
+	pb.RegisterEvaluatorServer(server, NewEvaluatorService(config))
+	ln, err := net.Listen("tcp", fmt.Sprintf(":%d", serverPort))
+	if err != nil {
+		log.Fatalf("TCP net listener initialization failed for port %v, got %s", serverPort, err.Error())
+	}
+
+	log.Printf("TCP net listener initialized for port %v", serverPort)
+	err = server.Serve(ln)
+	if err != nil {
+		log.Fatalf("gRPC serve failed, got %s", err.Error())
+	}
+}
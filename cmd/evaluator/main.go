@@ -0,0 +1,34 @@
+// Package main runs an evaluator that resolves overlapping proposals of the
+// match functions with a configurable policy instead of the default one of
+// Open Match.
+package main
+
+import (
+	"flag"
+	"log"
+
+	"sim/cmd/evaluator/evaluator"
+)
+
+const (
+	serverPort = 50508 // The port for hosting the Evaluator.
+)
+
+func main() {
+	config := evaluator.Config{}
+	policy := flag.String("policy", string(evaluator.PolicyScore), "which overlapping proposal wins: score or oldest")
+	flag.IntVar(&config.DefaultQuota, "quota", 0, "most matches a profile gets accepted per evaluation, zero does not limit it")
+	quotas := flag.String("profile-quotas", "", "comma separated profile=quota pairs overriding the quota")
+	flag.Parse()
+
+	var err error
+	if config.Policy, err = evaluator.ParsePolicy(*policy); err != nil {
+		log.Fatalf("Invalid policy, got %s", err.Error())
+	}
+	if config.Quotas, err = evaluator.ParseQuotas(*quotas); err != nil {
+		log.Fatalf("Invalid profile quotas, got %s", err.Error())
+	}
+
+	log.Printf("Starting evaluator with %+v", config)
+	evaluator.Start(serverPort, config)
+}
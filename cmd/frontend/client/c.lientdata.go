@@ -13,31 +13,30 @@ type PingData struct {
 	Ping   float64
 }
 
+// GetDesiredRegions returns the regions the client queues for using the
+// default region policy, sorted from best to worst ping.
 func GetDesiredRegions(Pings map[string]float64) []PingData {
-	returnData := []PingData{}
-	bestRegionName := ""
-	bestPing := 100000.0
-	for k := range Pings {
-		value := Pings[k]
-		if bestPing > value {
-			bestPing = value
-			bestRegionName = k
-		}
-		// allow to queue towards region that are smaller
-		if value < 300 {
-			returnData = append(returnData, PingData{
-				Region: k,
-				Ping:   value,
-			})
-		}
-	}
+	return GDefaultRegionPolicy.Select(Pings)
+}
 
-	// if we found 0 regions with below x ping, pick the one that was best
-	if len(returnData) == 0 {
-		returnData = append(returnData, PingData{Region: bestRegionName, Ping: bestPing})
+// GetDesiredRegionsForMode returns the regions the client queues for using the
+// region policy configured for the game mode.
+func GetDesiredRegionsForMode(mode string, Pings map[string]float64) []PingData {
+	return PolicyForMode(mode).Select(Pings)
+}
+
+// sortedPings returns every region sorted from best to worst ping, ties are
+// broken by region name so the result does not depend on map order.
+func sortedPings(Pings map[string]float64) []PingData {
+	returnData := []PingData{}
+	for k, v := range Pings {
+		returnData = append(returnData, PingData{Region: k, Ping: v})
 	}
 
 	sort.SliceStable(returnData, func(i int, j int) bool {
+		if returnData[i].Ping == returnData[j].Ping {
+			return returnData[i].Region < returnData[j].Region
+		}
 		return returnData[i].Ping < returnData[j].Ping
 	})
 
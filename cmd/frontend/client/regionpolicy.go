@@ -0,0 +1,166 @@
+package client
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// RegionPolicy decides which regions a client queues for based on its pings.
+// Policies always return at least the best region if there are any pings, and
+// the regions are sorted from best to worst ping.
+type RegionPolicy interface {
+	Select(Pings map[string]float64) []PingData
+}
+
+// AbsoluteThreshold queues for every region below MaxPing.
+type AbsoluteThreshold struct {
+	MaxPing float64
+}
+
+func (p AbsoluteThreshold) Select(Pings map[string]float64) []PingData {
+	sorted := sortedPings(Pings)
+	return keepBest(sorted, func(d PingData) bool {
+		return d.Ping < p.MaxPing
+	})
+}
+
+// RelativeToBest queues for every region within Margin of the best ping.
+type RelativeToBest struct {
+	Margin float64
+}
+
+func (p RelativeToBest) Select(Pings map[string]float64) []PingData {
+	sorted := sortedPings(Pings)
+	if len(sorted) == 0 {
+		return sorted
+	}
+	best := sorted[0].Ping
+	return keepBest(sorted, func(d PingData) bool {
+		return d.Ping <= best+p.Margin
+	})
+}
+
+// TopN queues for the N regions with the best ping.
+type TopN struct {
+	N int
+}
+
+func (p TopN) Select(Pings map[string]float64) []PingData {
+	sorted := sortedPings(Pings)
+	if p.N >= 1 && len(sorted) > p.N {
+		return sorted[:p.N]
+	}
+	if p.N < 1 && len(sorted) > 1 {
+		return sorted[:1]
+	}
+	return sorted
+}
+
+// BestOnly queues for the single region with the best ping.
+type BestOnly struct{}
+
+func (p BestOnly) Select(Pings map[string]float64) []PingData {
+	return TopN{N: 1}.Select(Pings)
+}
+
+var (
+	// GDefaultRegionPolicy is used for game modes without a policy of their own.
+	GDefaultRegionPolicy RegionPolicy = AbsoluteThreshold{MaxPing: 300}
+	// GModeRegionPolicies holds the region policy per game mode.
+	GModeRegionPolicies = map[string]RegionPolicy{}
+)
+
+// PolicyForMode returns the region policy of the game mode.
+func PolicyForMode(mode string) RegionPolicy {
+	if policy, ok := GModeRegionPolicies[mode]; ok {
+		return policy
+	}
+	return GDefaultRegionPolicy
+}
+
+// ParseRegionPolicy parses a policy written as absolute:<ms>, relative:<ms>,
+// top:<n> or best.
+func ParseRegionPolicy(spec string) (RegionPolicy, error) {
+	name, arg, hasArg := strings.Cut(strings.TrimSpace(spec), ":")
+	if name == "best" && !hasArg {
+		return BestOnly{}, nil
+	}
+	if !hasArg {
+		return nil, fmt.Errorf("region policy %q needs an argument", spec)
+	}
+
+	value, err := strconv.ParseFloat(arg, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid argument for region policy %q, got %w", spec, err)
+	}
+
+	switch name {
+	case "absolute":
+		return AbsoluteThreshold{MaxPing: value}, nil
+	case "relative":
+		return RelativeToBest{Margin: value}, nil
+	case "top":
+		if value < 1 {
+			return nil, fmt.Errorf("region policy %q needs at least one region", spec)
+		}
+		return TopN{N: int(value)}, nil
+	}
+	return nil, fmt.Errorf("unknown region policy %q", spec)
+}
+
+// ParseModeRegionPolicies parses a comma separated list of mode=policy pairs.
+func ParseModeRegionPolicies(spec string) (map[string]RegionPolicy, error) {
+	policies := map[string]RegionPolicy{}
+	if strings.TrimSpace(spec) == "" {
+		return policies, nil
+	}
+
+	for _, entry := range strings.Split(spec, ",") {
+		mode, policySpec, ok := strings.Cut(entry, "=")
+		if !ok {
+			return nil, fmt.Errorf("expected mode=policy, got %q", entry)
+		}
+		policy, err := ParseRegionPolicy(policySpec)
+		if err != nil {
+			return nil, err
+		}
+		policies[strings.TrimSpace(mode)] = policy
+	}
+	return policies, nil
+}
+
+// keepBest filters the sorted regions, falling back to the best region if
+// none of them pass.
+func keepBest(sorted []PingData, keep func(d PingData) bool) []PingData {
+	returnData := []PingData{}
+	for _, d := range sorted {
+		if keep(d) {
+			returnData = append(returnData, d)
+		}
+	}
+
+	if len(returnData) == 0 && len(sorted) > 0 {
+		returnData = append(returnData, sorted[0])
+	}
+	return returnData
+}
+
+// FixedRegions queues for exactly the listed regions that the client has a
+// ping for. It is used to keep the regions of a decoded ticket.
+type FixedRegions struct {
+	Regions []string
+}
+
+func (p FixedRegions) Select(Pings map[string]float64) []PingData {
+	returnData := []PingData{}
+	for _, d := range sortedPings(Pings) {
+		for _, region := range p.Regions {
+			if d.Region == region {
+				returnData = append(returnData, d)
+				break
+			}
+		}
+	}
+	return returnData
+}
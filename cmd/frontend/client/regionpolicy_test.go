@@ -0,0 +1,92 @@
+package client
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func regions(data []PingData) []string {
+	names := []string{}
+	for _, d := range data {
+		names = append(names, d.Region)
+	}
+	return names
+}
+
+func TestRegionPolicies(t *testing.T) {
+	pings := map[string]float64{"europe": 40, "us": 120, "asia": 250, "oceania": 120}
+
+	for _, tc := range []struct {
+		name     string
+		policy   RegionPolicy
+		pings    map[string]float64
+		expected []string
+	}{
+		{"absolute", AbsoluteThreshold{MaxPing: 150}, pings, []string{"europe", "oceania", "us"}},
+		{"absolute excludes the threshold", AbsoluteThreshold{MaxPing: 120}, pings, []string{"europe"}},
+		{"absolute keeps the best region", AbsoluteThreshold{MaxPing: 10}, pings, []string{"europe"}},
+		{"relative", RelativeToBest{Margin: 80}, pings, []string{"europe", "oceania", "us"}},
+		{"relative zero margin", RelativeToBest{Margin: 0}, pings, []string{"europe"}},
+		{"top", TopN{N: 2}, pings, []string{"europe", "oceania"}},
+		{"top more than available", TopN{N: 10}, pings, []string{"europe", "oceania", "us", "asia"}},
+		{"top without n", TopN{N: 0}, pings, []string{"europe"}},
+		{"best", BestOnly{}, pings, []string{"europe"}},
+		{"fixed", FixedRegions{Regions: []string{"asia", "us", "mars"}}, pings, []string{"us", "asia"}},
+		{"no pings", AbsoluteThreshold{MaxPing: 150}, map[string]float64{}, []string{}},
+		{"best without pings", BestOnly{}, nil, []string{}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expected, regions(tc.policy.Select(tc.pings)))
+		})
+	}
+}
+
+func TestParseRegionPolicy(t *testing.T) {
+	for _, tc := range []struct {
+		spec     string
+		expected RegionPolicy
+	}{
+		{"absolute:300", AbsoluteThreshold{MaxPing: 300}},
+		{" relative:50 ", RelativeToBest{Margin: 50}},
+		{"top:3", TopN{N: 3}},
+		{"best", BestOnly{}},
+	} {
+		policy, err := ParseRegionPolicy(tc.spec)
+		require.NoError(t, err, tc.spec)
+		require.Equal(t, tc.expected, policy, tc.spec)
+	}
+
+	for _, spec := range []string{"absolute", "absolute:x", "top:0", "best:1", "closest:10", ""} {
+		_, err := ParseRegionPolicy(spec)
+		require.Error(t, err, spec)
+	}
+}
+
+func TestModeRegionPolicies(t *testing.T) {
+	require := require.New(t)
+
+	policies, err := ParseModeRegionPolicies("bank_it=best, quick_cash=top:2")
+	require.NoError(err)
+	require.Equal(map[string]RegionPolicy{"bank_it": BestOnly{}, "quick_cash": TopN{N: 2}}, policies)
+
+	policies, err = ParseModeRegionPolicies("")
+	require.NoError(err)
+	require.Empty(policies)
+
+	_, err = ParseModeRegionPolicies("bank_it")
+	require.Error(err)
+	_, err = ParseModeRegionPolicies("bank_it=top:x")
+	require.Error(err)
+
+	defer func(defaultPolicy RegionPolicy, modePolicies map[string]RegionPolicy) {
+		GDefaultRegionPolicy, GModeRegionPolicies = defaultPolicy, modePolicies
+	}(GDefaultRegionPolicy, GModeRegionPolicies)
+	GDefaultRegionPolicy = TopN{N: 2}
+	GModeRegionPolicies = map[string]RegionPolicy{"bank_it": BestOnly{}}
+
+	pings := map[string]float64{"europe": 40, "us": 120}
+	require.Equal([]string{"europe"}, regions(GetDesiredRegionsForMode("bank_it", pings)))
+	require.Equal([]string{"europe", "us"}, regions(GetDesiredRegionsForMode("quick_cash", pings)))
+	require.Equal([]string{"europe", "us"}, regions(GetDesiredRegions(pings)))
+}
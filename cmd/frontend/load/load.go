@@ -0,0 +1,200 @@
+package load
+
+import (
+	"context"
+	"log"
+	"math"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"sim/internal/random"
+)
+
+// Config describes the arrival curve the generator follows. The rate at any
+// point in time is TicketsPerSecond scaled by the ramp and diurnal factors.
+type Config struct {
+	// Target number of tickets per second at full load.
+	TicketsPerSecond float64
+	// Number of workers creating tickets concurrently.
+	Workers int
+	// Poisson arrivals use exponential inter-arrival times, otherwise arrivals
+	// are spaced evenly.
+	Poisson bool
+	// Time to linearly ramp from zero to full load.
+	RampUp time.Duration
+	// Time to linearly ramp from full load to zero at the end of the run.
+	// Only used when Duration is set.
+	RampDown time.Duration
+	// Total length of the run, zero runs until the context is cancelled.
+	Duration time.Duration
+	// Relative amplitude (0-1) of the sinusoidal day/night curve.
+	DiurnalAmplitude float64
+	// Length of one simulated day.
+	DiurnalPeriod time.Duration
+	// Seed of the arrival schedule and of the sources handed to the workers.
+	Seed int64
+}
+
+// Generator schedules ticket arrivals and hands them to a pool of workers.
+type Generator struct {
+	config  Config
+	rng     *rand.Rand
+	dropped int64
+	// pending is the fraction of an arrival accumulated by evenly spaced
+	// candidates.
+	pending float64
+	clock   clock
+}
+
+// clock is the time source of the schedule, tests replace it with a virtual
+// one.
+type clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time                         { return time.Now() }
+func (realClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+
+// idleWait is how long the scheduler sleeps when the peak rate is zero.
+const idleWait = 100 * time.Millisecond
+
+func NewGenerator(config Config) *Generator {
+	if config.Workers < 1 {
+		config.Workers = 1
+	}
+	return &Generator{config: config, rng: random.New(config.Seed), clock: realClock{}}
+}
+
+// RateAt returns the target arrival rate in tickets per second at the given
+// time since the start of the run.
+func (g *Generator) RateAt(elapsed time.Duration) float64 {
+	c := g.config
+	if c.Duration > 0 && elapsed >= c.Duration {
+		return 0
+	}
+
+	scale := 1.0
+	if c.RampUp > 0 && elapsed < c.RampUp {
+		scale = math.Min(scale, float64(elapsed)/float64(c.RampUp))
+	}
+	if c.Duration > 0 && c.RampDown > 0 {
+		remaining := c.Duration - elapsed
+		if remaining < c.RampDown {
+			scale = math.Min(scale, float64(remaining)/float64(c.RampDown))
+		}
+	}
+	if c.DiurnalAmplitude > 0 && c.DiurnalPeriod > 0 {
+		phase := 2 * math.Pi * float64(elapsed) / float64(c.DiurnalPeriod)
+		scale *= 1 + c.DiurnalAmplitude*math.Sin(phase)
+	}
+
+	return math.Max(0, c.TicketsPerSecond*scale)
+}
+
+// peakRate is an upper bound of RateAt over the whole run.
+func (g *Generator) peakRate() float64 {
+	peak := g.config.TicketsPerSecond
+	if g.config.DiurnalAmplitude > 0 && g.config.DiurnalPeriod > 0 {
+		peak *= 1 + g.config.DiurnalAmplitude
+	}
+	return math.Max(0, peak)
+}
+
+// nextCandidate returns the wait until the next candidate arrival. Candidates
+// are proposed at the peak rate and thinned by accept, so the arrivals follow
+// the rate curve even where it changes quickly, for example at the start of a
+// ramp.
+func (g *Generator) nextCandidate(peak float64) time.Duration {
+	if peak <= 0 {
+		return idleWait
+	}
+	interval := 1 / peak
+	if g.config.Poisson {
+		interval = g.rng.ExpFloat64() / peak
+	}
+	return time.Duration(interval * float64(time.Second))
+}
+
+// accept decides whether a candidate becomes an arrival. Poisson arrivals keep
+// a candidate with probability rate/peak, evenly spaced arrivals accumulate
+// the fraction and arrive whenever it adds up to one.
+func (g *Generator) accept(rate float64, peak float64) bool {
+	if rate <= 0 || peak <= 0 {
+		return false
+	}
+	if g.config.Poisson {
+		return g.rng.Float64() < rate/peak
+	}
+	g.pending += rate / peak
+	if g.pending >= 1 {
+		g.pending--
+		return true
+	}
+	return false
+}
+
+// Dropped returns how many arrivals were skipped because every worker was busy.
+func (g *Generator) Dropped() int64 {
+	return atomic.LoadInt64(&g.dropped)
+}
+
+// Run schedules arrivals until the context is cancelled or the configured
+// duration has passed, calling work once per arrival on one of the workers.
+// Every arrival gets its own random source derived from the seed, so the
+// generated tickets do not depend on which worker picks them up.
+func (g *Generator) Run(ctx context.Context, work func(ctx context.Context, r *rand.Rand)) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	arrivals := make(chan *rand.Rand, g.config.Workers)
+	var wg sync.WaitGroup
+	for i := 0; i < g.config.Workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for r := range arrivals {
+				work(ctx, r)
+			}
+		}()
+	}
+
+	peak := g.peakRate()
+	start := g.clock.Now()
+	lastLog := start
+	next := g.clock.After(0)
+	for {
+		select {
+		case <-ctx.Done():
+			close(arrivals)
+			wg.Wait()
+			return
+		case <-next:
+		}
+
+		elapsed := g.clock.Now().Sub(start)
+		if g.config.Duration > 0 && elapsed >= g.config.Duration {
+			// Work still in progress is cancelled as well.
+			cancel()
+			continue
+		}
+		rate := g.RateAt(elapsed)
+		if g.accept(rate, peak) {
+			select {
+			case arrivals <- random.Derive(g.rng):
+			default:
+				atomic.AddInt64(&g.dropped, 1)
+			}
+		}
+
+		if g.clock.Now().Sub(lastLog) > 10*time.Second {
+			log.Printf("Load generator at %s: target rate %.2f tickets/s, dropped %d arrivals", elapsed.Truncate(time.Second), rate, g.Dropped())
+			lastLog = g.clock.Now()
+		}
+		next = g.clock.After(g.nextCandidate(peak))
+	}
+}
@@ -0,0 +1,92 @@
+package load
+
+import (
+	"context"
+	"math/rand"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// simulate runs the arrival schedule against a virtual clock and returns the
+// times of the arrivals.
+func simulate(g *Generator, length time.Duration) []time.Duration {
+	arrivals := []time.Duration{}
+	peak := g.peakRate()
+	for elapsed := time.Duration(0); elapsed < length; elapsed += g.nextCandidate(peak) {
+		if g.accept(g.RateAt(elapsed), peak) {
+			arrivals = append(arrivals, elapsed)
+		}
+	}
+	return arrivals
+}
+
+func TestRateAt(t *testing.T) {
+	require := require.New(t)
+
+	g := NewGenerator(Config{TicketsPerSecond: 10, RampUp: time.Minute, RampDown: time.Minute, Duration: 10 * time.Minute})
+	require.Equal(0.0, g.RateAt(0))
+	require.InDelta(5, g.RateAt(30*time.Second), 1e-9)
+	require.Equal(10.0, g.RateAt(5*time.Minute))
+	require.InDelta(5, g.RateAt(9*time.Minute+30*time.Second), 1e-9)
+	require.Equal(0.0, g.RateAt(10*time.Minute))
+
+	g = NewGenerator(Config{TicketsPerSecond: 10, DiurnalAmplitude: 0.5, DiurnalPeriod: 4 * time.Hour})
+	require.InDelta(15, g.RateAt(time.Hour), 1e-9)
+	require.InDelta(5, g.RateAt(3*time.Hour), 1e-9)
+	require.Equal(15.0, g.peakRate())
+}
+
+func TestRampUpArrivals(t *testing.T) {
+	require := require.New(t)
+
+	// The rate rises linearly to 10/s over 10 minutes, so 3000 tickets are
+	// expected and the first one after about 11 seconds.
+	for _, poisson := range []bool{false, true} {
+		g := NewGenerator(Config{TicketsPerSecond: 10, RampUp: 10 * time.Minute, Duration: 10 * time.Minute, Poisson: poisson, Seed: 1})
+		arrivals := simulate(g, 10*time.Minute)
+		require.InEpsilon(3000, len(arrivals), 0.05, "poisson %v", poisson)
+		require.Less(arrivals[0], time.Minute, "poisson %v", poisson)
+
+		// Half of the tickets arrive after 7 minutes, where the integral of
+		// the ramp reaches half of its total.
+		late := 0
+		for _, arrival := range arrivals {
+			if arrival > 7*time.Minute+4*time.Second {
+				late++
+			}
+		}
+		require.InEpsilon(len(arrivals)/2, late, 0.1, "poisson %v", poisson)
+	}
+}
+
+// fakeClock jumps ahead whenever the generator waits.
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func (c *fakeClock) After(d time.Duration) <-chan time.Time {
+	c.now = c.now.Add(d)
+	ready := make(chan time.Time, 1)
+	ready <- c.now
+	return ready
+}
+
+func TestRun(t *testing.T) {
+	g := NewGenerator(Config{TicketsPerSecond: 200, Workers: 2, Duration: 200 * time.Millisecond})
+	g.clock = &fakeClock{now: time.Unix(0, 0)}
+
+	// Evenly spaced arrivals every 5ms during 200ms, every one is either
+	// handed to a worker or dropped.
+	var count int64
+	g.Run(context.Background(), func(ctx context.Context, r *rand.Rand) {
+		atomic.AddInt64(&count, 1)
+	})
+	require.Equal(t, int64(40), atomic.LoadInt64(&count)+g.Dropped())
+}
@@ -14,12 +14,26 @@
 
 package main
 
-// The Frontend in this tutorial continuously creates Tickets in batches in Open Match.
+// The Frontend in this tutorial continuously creates Tickets in Open Match,
+// following the arrival curve configured through the command line flags.
 
 import (
 	"context"
+	"flag"
 	"log"
+	"math/rand"
+	"slices"
+	"sync/atomic"
+	"time"
 
+	"sim/cmd/frontend/client"
+	"sim/cmd/frontend/load"
+	"sim/cmd/frontend/population"
+	"sim/cmd/frontend/trace"
+	"sim/cmd/frontend/tracker"
+	"sim/internal/geo"
+	"sim/internal/random"
+	"sim/internal/scenario"
 	"sim/internal/ticket"
 
 	"google.golang.org/grpc"
@@ -29,11 +43,103 @@ import (
 const (
 	// The endpoint for the Open Match Frontend service.
 	omFrontendEndpoint = "open-match-frontend.open-match.svc.cluster.local:50504"
-	// Number of tickets created per iteration
-	ticketsGeneratedPerIteration = 20
 )
 
 func main() {
+	loadConfig := load.Config{}
+	flag.Float64Var(&loadConfig.TicketsPerSecond, "rate", 20, "target tickets created per second")
+	flag.IntVar(&loadConfig.Workers, "workers", 8, "number of concurrent ticket creation workers")
+	flag.BoolVar(&loadConfig.Poisson, "poisson", true, "use Poisson arrivals instead of evenly spaced ones")
+	flag.DurationVar(&loadConfig.RampUp, "ramp-up", 0, "time to ramp up from zero to the target rate")
+	flag.DurationVar(&loadConfig.RampDown, "ramp-down", 0, "time to ramp down to zero at the end of the run")
+	flag.DurationVar(&loadConfig.Duration, "duration", 0, "length of the run, zero runs forever")
+	flag.Float64Var(&loadConfig.DiurnalAmplitude, "diurnal-amplitude", 0, "relative amplitude (0-1) of the day/night curve")
+	flag.DurationVar(&loadConfig.DiurnalPeriod, "diurnal-period", 24*time.Hour, "length of one simulated day")
+	maxWatchers := flag.Int("max-watchers", 1000, "maximum number of concurrent assignment watchers")
+	ticketTimeout := flag.Duration("ticket-timeout", 30*time.Minute, "time after which an unassigned ticket is deleted and its players released, zero waits forever")
+	populationConfig := population.Config{}
+	flag.IntVar(&populationConfig.Size, "population", 0, "number of persistent players, zero creates a fresh player per ticket")
+	flag.DurationVar(&populationConfig.GameDuration, "game-duration", 10*time.Minute, "mean length of a simulated game")
+	flag.Float64Var(&populationConfig.LogoffChance, "logoff-chance", 0.2, "chance a player logs off after a game")
+	flag.DurationVar(&populationConfig.OfflineDuration, "offline-duration", 30*time.Minute, "mean time a player stays offline")
+	partyChance := flag.Float64("party-chance", 0, "chance that a ticket is a party instead of a single player")
+	maxPartySize := flag.Int("max-party-size", 4, "largest party size, parties have between 2 and this many members")
+	partyAggregation := flag.String("party-aggregation", string(ticket.AggregateMax), "how party skill and pings are combined: max, average or weighted")
+	patienceModel := flag.String("patience-model", "none", "how long clients wait before cancelling: none, fixed, exponential or player")
+	flag.DurationVar(&populationConfig.Patience, "patience", 5*time.Minute, "mean patience of a client in the queue")
+	abandonInterval := flag.Duration("abandon-interval", time.Second, "how often tickets are checked for abandonment")
+	tracePath := flag.String("trace", "", "replay arrivals from a .jsonl or .csv trace instead of generating them")
+	traceScale := flag.Float64("trace-scale", 1, "speed up factor for trace replay")
+	distributions := flag.String("distributions", "", "YAML or JSON file with the skill and ping distributions")
+	latencyModel := flag.String("latency-model", "", "geographic latency model file, \"default\" for the built in one, empty uses the ping distributions")
+	regionPolicy := flag.String("region-policy", "absolute:300", "default region policy: absolute:<ms>, relative:<ms>, top:<n> or best")
+	modeRegionPolicies := flag.String("mode-region-policies", "", "comma separated mode=policy pairs overriding the default region policy")
+	expansionSteps := flag.String("expansion-steps", "", "comma separated after/policy/skill steps widening waiting tickets, for example 30s/relative:100/100,90s/top:3/250")
+	expansionInterval := flag.Duration("expansion-interval", time.Second, "how often tickets are checked for expansion")
+	seedFlag := flag.Int64("seed", 0, "seed of the simulation, zero picks one from the clock")
+	summaryInterval := flag.Duration("summary-interval", 30*time.Second, "how often the queue time summary is logged")
+	scenarioPath := flag.String("scenario", "", "YAML or JSON scenario file of the director, queue times are reported per profile of it")
+	flag.Parse()
+
+	seed := random.NewSeed(*seedFlag)
+	log.Printf("Running with seed %d", seed)
+	rng := random.New(seed)
+	loadConfig.Seed = rng.Int63()
+
+	if *distributions != "" {
+		if err := random.LoadDistributions(*distributions); err != nil {
+			log.Fatalf("Failed to load distributions, got %s", err.Error())
+		}
+		log.Printf("Loaded distributions from %s", *distributions)
+	}
+
+	if *scenarioPath != "" {
+		config, err := scenario.Load(*scenarioPath)
+		if err != nil {
+			log.Fatalf("Failed to load scenario, got %s", err.Error())
+		}
+		tracker.GScenario = config
+		log.Printf("Loaded scenario from %s", *scenarioPath)
+	}
+
+	if *latencyModel != "" {
+		model := geo.DefaultLatencyModel()
+		if *latencyModel != "default" {
+			var err error
+			if model, err = geo.LoadLatencyModel(*latencyModel); err != nil {
+				log.Fatalf("Failed to load latency model, got %s", err.Error())
+			}
+		}
+		for _, dc := range model.Datacenters {
+			if !slices.Contains(tracker.GScenario.Regions, dc.Region) {
+				log.Printf("Datacenter region %s has no match profiles, tickets towards it will not be matched", dc.Region)
+			}
+		}
+		geo.GLatencyModel = model
+		log.Printf("Using geographic latency model with %d datacenters", len(model.Datacenters))
+	}
+
+	defaultPolicy, err := client.ParseRegionPolicy(*regionPolicy)
+	if err != nil {
+		log.Fatalf("Invalid region policy, got %s", err.Error())
+	}
+	modePolicies, err := client.ParseModeRegionPolicies(*modeRegionPolicies)
+	if err != nil {
+		log.Fatalf("Invalid mode region policies, got %s", err.Error())
+	}
+	client.GDefaultRegionPolicy = defaultPolicy
+	client.GModeRegionPolicies = modePolicies
+
+	steps, err := tracker.ParseExpansionSteps(*expansionSteps)
+	if err != nil {
+		log.Fatalf("Invalid expansion steps, got %s", err.Error())
+	}
+
+	aggregation, err := ticket.ParseAggregationStrategy(*partyAggregation)
+	if err != nil {
+		log.Fatalf("Invalid party configuration, got %s", err.Error())
+	}
+
 	// Connect to Open Match Frontend.
 	conn, err := grpc.Dial(omFrontendEndpoint, grpc.WithInsecure())
 	if err != nil {
@@ -43,19 +149,173 @@ func main() {
 	defer conn.Close()
 	fe := pb.NewFrontendServiceClient(conn)
 
-	log.Printf("Amount of tickets attempted to be generated %d", ticketsGeneratedPerIteration)
+	var players *population.Population
+	if populationConfig.Size > 0 {
+		players = population.NewPopulation(populationConfig, random.Derive(rng))
+		log.Printf("Created population with %+v", populationConfig)
+	}
+
+	var lookupPatience func(playerID string) (time.Duration, bool)
+	if players != nil {
+		lookupPatience = players.Patience
+	}
+	patience, err := tracker.NewPatienceModel(*patienceModel, populationConfig.Patience, lookupPatience)
+	if err != nil {
+		log.Fatalf("Failed to create patience model, got %s", err.Error())
+	}
+
+	ticketTracker := tracker.NewTracker(patience)
+	watcher := tracker.NewWatcher(fe, ticketTracker, *maxWatchers)
+	watcher.Timeout = *ticketTimeout
+
+	watcher.OnAssigned = func(record *tracker.TicketRecord) {
+		log.Printf("Ticket %s assigned to %s after %s", record.ID, record.Connection, record.QueueTime())
+		if players != nil {
+			for _, id := range record.ClientData.PlayerIDs() {
+				players.StartMatch(id)
+			}
+		}
+	}
+
+	// Tickets that can not be watched anymore are given up, so their players
+	// do not stay in the queue forever.
+	watcher.OnFailed = func(record *tracker.TicketRecord, err error) {
+		if _, err := fe.DeleteTicket(context.Background(), &pb.DeleteTicketRequest{TicketId: record.ID}); err != nil {
+			log.Printf("Failed to delete failed ticket %s, got %s", record.ID, err.Error())
+		}
+		if players != nil {
+			for _, id := range record.ClientData.PlayerIDs() {
+				players.Release(id)
+			}
+		}
+	}
+
+	if patience != nil {
+		abandoner := tracker.NewAbandoner(fe, ticketTracker)
+		abandoner.OnAbandoned = func(record *tracker.TicketRecord) {
+			if players != nil {
+				for _, id := range record.ClientData.PlayerIDs() {
+					players.Release(id)
+				}
+			}
+		}
+		go abandoner.Run(context.Background(), *abandonInterval)
+	}
+
+	if len(steps) > 0 {
+		expander := tracker.NewExpander(fe, ticketTracker, watcher, steps)
+		expander.OnDropped = func(record *tracker.TicketRecord) {
+			if players != nil {
+				for _, id := range record.ClientData.PlayerIDs() {
+					players.Release(id)
+				}
+			}
+		}
+		go expander.Run(context.Background(), *expansionInterval)
+	}
+
+	go func() {
+		for range time.Tick(*summaryInterval) {
+			ticketTracker.LogSummary()
+			if players != nil {
+				players.LogCounts()
+			}
+		}
+	}()
+
+	var created, failed, noIdlePlayers int64
+	createTicket := func(ctx context.Context, r *rand.Rand, clientData ticket.ClientMatchmakingData) {
+		t, err := ticket.MakeTicket(clientData)
+		if err != nil {
+			atomic.AddInt64(&failed, 1)
+			if players != nil {
+				for _, id := range clientData.PlayerIDs() {
+					players.Release(id)
+				}
+			}
+			log.Printf("Failed to encode Ticket, got %s for client %+v", err.Error(), clientData)
+			return
+		}
 
-	for {
-		clientData := ticket.CreateRandomMatchmakingData()
 		req := &pb.CreateTicketRequest{
-			Ticket: ticket.MakeTicket(clientData),
+			Ticket: t,
 		}
-		resp, err := fe.CreateTicket(context.Background(), req)
+		resp, err := fe.CreateTicket(ctx, req)
 		if err != nil {
+			atomic.AddInt64(&failed, 1)
+			if players != nil {
+				for _, id := range clientData.PlayerIDs() {
+					players.Release(id)
+				}
+			}
 			log.Printf("Failed to Create Ticket, got %s for client %+v", err.Error(), clientData)
-			continue
+			return
 		}
 
+		atomic.AddInt64(&created, 1)
+		ticketTracker.Add(resp.GetId(), clientData, time.Now(), r)
+		watcher.Watch(context.Background(), resp.GetId())
 		log.Printf("Created ticket %s with client %+v", resp.GetId(), clientData)
 	}
+
+	if *tracePath != "" {
+		records, err := trace.Load(*tracePath)
+		if err != nil {
+			log.Fatalf("Failed to load trace, got %s", err.Error())
+		}
+
+		log.Printf("Replaying %d arrivals from %s at %.2fx speed", len(records), *tracePath, *traceScale)
+		trace.Replay(context.Background(), records, *traceScale, loadConfig.Workers, random.Derive(rng), func(ctx context.Context, record trace.Record, r *rand.Rand) {
+			clientData, err := record.ClientData(aggregation)
+			if err != nil {
+				atomic.AddInt64(&failed, 1)
+				log.Printf("Failed to create client data for arrival at %vs, got %s", record.Timestamp, err.Error())
+				return
+			}
+			createTicket(ctx, r, clientData)
+		})
+
+		log.Printf("Trace replay with seed %d finished, created %d tickets, failed %d", seed, atomic.LoadInt64(&created), atomic.LoadInt64(&failed))
+		ticketTracker.LogSummary()
+		return
+	}
+
+	log.Printf("Generating tickets with %+v", loadConfig)
+	generator := load.NewGenerator(loadConfig)
+	generator.Run(context.Background(), func(ctx context.Context, r *rand.Rand) {
+		partySize := 1
+		if *maxPartySize > 1 && r.Float64() < *partyChance {
+			partySize = 2 + r.Intn(*maxPartySize-1)
+		}
+
+		var clientData ticket.ClientMatchmakingData
+		var err error
+		if players != nil {
+			party, ok := players.AcquireParty(partySize)
+			if !ok {
+				atomic.AddInt64(&noIdlePlayers, 1)
+				return
+			}
+			if clientData, err = population.PartyMatchmakingData(party, aggregation); err != nil {
+				for _, player := range party {
+					players.Release(player.ID)
+				}
+			}
+		} else if partySize > 1 {
+			clientData, err = ticket.CreateRandomParty(r, partySize, aggregation)
+		} else {
+			clientData = ticket.CreateRandomMatchmakingData(r)
+		}
+		if err != nil {
+			atomic.AddInt64(&failed, 1)
+			log.Printf("Failed to create party of %d, got %s", partySize, err.Error())
+			return
+		}
+
+		createTicket(ctx, r, clientData)
+	})
+
+	log.Printf("Load generation with seed %d finished, created %d tickets, failed %d, dropped %d arrivals, %d arrivals without idle players",
+		seed, atomic.LoadInt64(&created), atomic.LoadInt64(&failed), generator.Dropped(), atomic.LoadInt64(&noIdlePlayers))
+	ticketTracker.LogSummary()
 }
@@ -0,0 +1,275 @@
+package population
+
+import (
+	"fmt"
+	"log"
+	"math/rand"
+	"sync"
+	"time"
+
+	"sim/cmd/frontend/client"
+	"sim/internal/ticket"
+)
+
+// State is where a player currently is in its session lifecycle.
+type State int
+
+const (
+	Offline State = iota
+	Idle
+	Queueing
+	InMatch
+)
+
+func (s State) String() string {
+	switch s {
+	case Offline:
+		return "offline"
+	case Idle:
+		return "idle"
+	case Queueing:
+		return "queueing"
+	case InMatch:
+		return "in_match"
+	}
+	return "unknown"
+}
+
+// Player is a simulated player that keeps its identity, skill, pings and
+// preferences between matches.
+type Player struct {
+	ID       string
+	Skill    float64
+	Pings    map[string]float64
+	GameMode string
+	Trusted  string
+	Password string
+	Beginner bool
+	// Patience is how long the player waits in the queue before giving up.
+	Patience   time.Duration
+	State      State
+	MatchCount int
+}
+
+// MatchmakingData returns the data the player queues with.
+func (p *Player) MatchmakingData() ticket.ClientMatchmakingData {
+	pings := make(map[string]float64, len(p.Pings))
+	for region, ping := range p.Pings {
+		pings[region] = ping
+	}
+	return ticket.ClientMatchmakingData{
+		PlayerID: p.ID,
+		RegionData: client.ClientRegionData{
+			Pings: pings,
+		},
+		Trusted:  p.Trusted,
+		Password: p.Password,
+		Skill:    p.Skill,
+		GameMode: p.GameMode,
+		Beginner: p.Beginner,
+	}
+}
+
+// Config controls the size of the population and the length of sessions.
+type Config struct {
+	// Number of players in the population.
+	Size int
+	// Mean length of a simulated game once a player has been assigned.
+	GameDuration time.Duration
+	// Chance that a player logs off after finishing a game.
+	LogoffChance float64
+	// Mean time a player stays offline before logging back in.
+	OfflineDuration time.Duration
+	// Mean patience of a player, every player draws its own patience from an
+	// exponential distribution around it.
+	Patience time.Duration
+}
+
+// Population owns all simulated players and moves them between states.
+type Population struct {
+	config Config
+	mu     sync.Mutex
+	// rng is only used while holding mu.
+	rng     *rand.Rand
+	players map[string]*Player
+	// idle holds the IDs of players that are ready to queue, idleIndex maps a
+	// player ID to its position in idle so it can be removed in constant time.
+	idle      []string
+	idleIndex map[string]int
+}
+
+// NewPopulation creates a population of players with random matchmaking data.
+// Every player starts out idle.
+func NewPopulation(config Config, r *rand.Rand) *Population {
+	p := &Population{
+		config:    config,
+		rng:       r,
+		players:   make(map[string]*Player, config.Size),
+		idleIndex: make(map[string]int, config.Size),
+	}
+
+	for i := 0; i < config.Size; i++ {
+		data := ticket.CreateRandomMatchmakingData(r)
+		player := &Player{
+			ID:       fmt.Sprintf("player-%06d", i),
+			Skill:    data.Skill,
+			Pings:    data.RegionData.Pings,
+			GameMode: data.GameMode,
+			Trusted:  data.Trusted,
+			Password: data.Password,
+			Beginner: data.Beginner,
+			Patience: time.Duration(r.ExpFloat64() * float64(config.Patience)),
+		}
+		p.players[player.ID] = player
+		p.setStateLocked(player, Idle)
+	}
+
+	return p
+}
+
+// Acquire picks a random idle player and moves it into the queue. Returns
+// false if no player is idle.
+func (p *Population) Acquire() (*Player, bool) {
+	players, ok := p.AcquireParty(1)
+	if !ok {
+		return nil, false
+	}
+	return players[0], true
+}
+
+// AcquireParty picks size random idle players and moves them into the queue
+// together. Returns false if there are not enough idle players.
+func (p *Population) AcquireParty(size int) ([]*Player, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if size < 1 || len(p.idle) < size {
+		return nil, false
+	}
+	players := []*Player{}
+	for i := 0; i < size; i++ {
+		player := p.players[p.idle[p.rng.Intn(len(p.idle))]]
+		p.setStateLocked(player, Queueing)
+		players = append(players, player)
+	}
+	return players, true
+}
+
+// PartyMatchmakingData returns the data a group of players queues with, the
+// first player leads the party. A single player queues without a party.
+func PartyMatchmakingData(players []*Player, strategy ticket.AggregationStrategy) (ticket.ClientMatchmakingData, error) {
+	switch len(players) {
+	case 0:
+		return ticket.ClientMatchmakingData{}, fmt.Errorf("party without players")
+	case 1:
+		return players[0].MatchmakingData(), nil
+	}
+
+	members := []ticket.PartyMember{}
+	for _, player := range players {
+		data := player.MatchmakingData()
+		members = append(members, ticket.PartyMember{
+			PlayerID: data.PlayerID,
+			Skill:    data.Skill,
+			Pings:    data.RegionData.Pings,
+		})
+	}
+	return ticket.MakeParty(players[0].MatchmakingData(), members, strategy)
+}
+
+// Release returns a queueing player to idle, for example when its ticket could
+// not be created or was cancelled.
+func (p *Population) Release(id string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if player, ok := p.players[id]; ok && player.State == Queueing {
+		p.setStateLocked(player, Idle)
+	}
+}
+
+// Patience returns the patience of a player.
+func (p *Population) Patience(id string) (time.Duration, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	player, ok := p.players[id]
+	if !ok {
+		return 0, false
+	}
+	return player.Patience, true
+}
+
+// StartMatch moves a queueing player into a match. Once the simulated game is
+// over the player goes back to idle or logs off.
+func (p *Population) StartMatch(id string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	player, ok := p.players[id]
+	if !ok || player.State != Queueing {
+		return
+	}
+	player.MatchCount++
+	p.setStateLocked(player, InMatch)
+	time.AfterFunc(jitter(p.rng, p.config.GameDuration), func() { p.endMatch(id) })
+}
+
+func (p *Population) endMatch(id string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	player := p.players[id]
+	if p.rng.Float64() >= p.config.LogoffChance {
+		p.setStateLocked(player, Idle)
+		return
+	}
+
+	p.setStateLocked(player, Offline)
+	time.AfterFunc(jitter(p.rng, p.config.OfflineDuration), func() {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		p.setStateLocked(player, Idle)
+	})
+}
+
+// Counts returns how many players are in each state.
+func (p *Population) Counts() map[State]int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	counts := map[State]int{}
+	for _, player := range p.players {
+		counts[player.State]++
+	}
+	return counts
+}
+
+// LogCounts prints how many players are in each state.
+func (p *Population) LogCounts() {
+	counts := p.Counts()
+	log.Printf("Population of %d: %d idle, %d queueing, %d in match, %d offline",
+		p.config.Size, counts[Idle], counts[Queueing], counts[InMatch], counts[Offline])
+}
+
+func (p *Population) setStateLocked(player *Player, state State) {
+	if player.State == Idle && state != Idle {
+		index := p.idleIndex[player.ID]
+		last := p.idle[len(p.idle)-1]
+		p.idle[index] = last
+		p.idleIndex[last] = index
+		p.idle = p.idle[:len(p.idle)-1]
+		delete(p.idleIndex, player.ID)
+	}
+	if state == Idle && player.State != Idle {
+		p.idleIndex[player.ID] = len(p.idle)
+		p.idle = append(p.idle, player.ID)
+	}
+	player.State = state
+}
+
+// jitter returns a duration uniformly spread between half and one and a half
+// times the mean.
+func jitter(r *rand.Rand, mean time.Duration) time.Duration {
+	return time.Duration((0.5 + r.Float64()) * float64(mean))
+}
@@ -0,0 +1,115 @@
+package population
+
+import (
+	"testing"
+	"time"
+
+	"sim/internal/random"
+	"sim/internal/ticket"
+
+	"github.com/stretchr/testify/require"
+)
+
+// newTestPopulation creates a population whose games and offline times do not
+// end during a test, endMatch is called by hand instead.
+func newTestPopulation(size int, logoffChance float64) *Population {
+	return NewPopulation(Config{
+		Size:            size,
+		GameDuration:    time.Hour,
+		LogoffChance:    logoffChance,
+		OfflineDuration: time.Hour,
+		Patience:        time.Minute,
+	}, random.New(1))
+}
+
+// requireIdleConsistent checks that the idle list holds exactly the idle
+// players, once each.
+func requireIdleConsistent(t *testing.T, p *Population) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	idle := 0
+	for _, player := range p.players {
+		if player.State == Idle {
+			idle++
+			require.Equal(t, player.ID, p.idle[p.idleIndex[player.ID]])
+		}
+	}
+	require.Len(t, p.idle, idle)
+	require.Len(t, p.idleIndex, idle)
+}
+
+func TestStateTransitions(t *testing.T) {
+	release := func(p *Population, id string) { p.Release(id) }
+	start := func(p *Population, id string) { p.StartMatch(id) }
+	end := func(p *Population, id string) { p.endMatch(id) }
+
+	tests := []struct {
+		name         string
+		logoffChance float64
+		steps        []func(p *Population, id string)
+		want         State
+		matches      int
+	}{
+		{name: "acquired", want: Queueing},
+		// The ticket could not be created or its watch failed.
+		{name: "released after failure", steps: []func(*Population, string){release}, want: Idle},
+		{name: "released twice", steps: []func(*Population, string){release, release}, want: Idle},
+		{name: "matched", steps: []func(*Population, string){start}, want: InMatch, matches: 1},
+		{name: "release while in match is ignored", steps: []func(*Population, string){start, release}, want: InMatch, matches: 1},
+		{name: "started twice", steps: []func(*Population, string){start, start}, want: InMatch, matches: 1},
+		{name: "start after release is ignored", steps: []func(*Population, string){release, start}, want: Idle},
+		{name: "game over", steps: []func(*Population, string){start, end}, want: Idle, matches: 1},
+		{name: "logged off", logoffChance: 1, steps: []func(*Population, string){start, end}, want: Offline, matches: 1},
+		{name: "release while offline is ignored", logoffChance: 1, steps: []func(*Population, string){start, end, release}, want: Offline, matches: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := newTestPopulation(3, tt.logoffChance)
+			player, ok := p.Acquire()
+			require.True(t, ok)
+
+			for _, step := range tt.steps {
+				step(p, player.ID)
+			}
+			require.Equal(t, tt.want, player.State)
+			require.Equal(t, tt.matches, player.MatchCount)
+			requireIdleConsistent(t, p)
+		})
+	}
+}
+
+func TestAcquireParty(t *testing.T) {
+	require := require.New(t)
+
+	p := newTestPopulation(3, 0)
+	_, ok := p.AcquireParty(4)
+	require.False(ok, "Not enough idle players")
+	_, ok = p.AcquireParty(0)
+	require.False(ok)
+
+	party, ok := p.AcquireParty(3)
+	require.True(ok)
+	seen := map[string]bool{}
+	for _, player := range party {
+		require.Equal(Queueing, player.State)
+		require.False(seen[player.ID], "Player %s was picked twice", player.ID)
+		seen[player.ID] = true
+	}
+	_, ok = p.Acquire()
+	require.False(ok, "Nobody is idle anymore")
+
+	clientData, err := PartyMatchmakingData(party, ticket.AggregateMax)
+	require.NoError(err)
+	require.Equal(3, clientData.PartySize())
+	_, err = PartyMatchmakingData(nil, ticket.AggregateMax)
+	require.Error(err)
+
+	// Releasing the party makes its players available again.
+	for _, player := range party {
+		p.Release(player.ID)
+	}
+	require.Equal(map[State]int{Idle: 3}, p.Counts())
+	requireIdleConsistent(t, p)
+}
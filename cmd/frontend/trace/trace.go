@@ -0,0 +1,267 @@
+package trace
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"sim/cmd/frontend/client"
+	utils "sim/internal"
+	"sim/internal/random"
+	"sim/internal/ticket"
+)
+
+// Member is a party member inside a recorded arrival.
+type Member struct {
+	Skill float64            `json:"skill"`
+	Pings map[string]float64 `json:"pings"`
+}
+
+// Record is a single recorded arrival of a player or party.
+type Record struct {
+	// Seconds since the start of the trace.
+	Timestamp float64            `json:"timestamp"`
+	Skill     float64            `json:"skill"`
+	Pings     map[string]float64 `json:"pings"`
+	Mode      string             `json:"mode"`
+	Trusted   bool               `json:"trusted"`
+	Password  string             `json:"password"`
+	// Party holds every member of a party arrival, empty for single players.
+	Party []Member `json:"party"`
+}
+
+// ClientData converts the record into the matchmaking data of the ticket.
+func (r Record) ClientData(strategy ticket.AggregationStrategy) (ticket.ClientMatchmakingData, error) {
+	trusted := utils.GTrustedNameFalse
+	if r.Trusted {
+		trusted = utils.GTrustedNameTrue
+	}
+
+	clientData := ticket.ClientMatchmakingData{
+		RegionData: client.ClientRegionData{
+			Pings: r.Pings,
+		},
+		Trusted:  trusted,
+		Password: r.Password,
+		Skill:    r.Skill,
+		GameMode: r.Mode,
+	}
+	if len(r.Party) == 0 {
+		return clientData, nil
+	}
+
+	members := []ticket.PartyMember{}
+	for _, m := range r.Party {
+		members = append(members, ticket.PartyMember{Skill: m.Skill, Pings: m.Pings})
+	}
+	return ticket.MakeParty(clientData, members, strategy)
+}
+
+// Validate checks that the record can be turned into a ticket.
+func (r Record) Validate() error {
+	if r.Mode == "" {
+		return fmt.Errorf("missing mode")
+	}
+	if len(r.Pings) == 0 {
+		return fmt.Errorf("missing pings")
+	}
+	for _, m := range r.Party {
+		if len(m.Pings) == 0 {
+			return fmt.Errorf("missing pings for party member")
+		}
+	}
+	return nil
+}
+
+// Load reads a trace file, the format is picked from the file extension
+// (.jsonl or .csv). The records are returned sorted by timestamp.
+func Load(path string) ([]Record, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var records []Record
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".jsonl", ".json":
+		records, err = ReadJSONL(f)
+	case ".csv":
+		records, err = ReadCSV(f)
+	default:
+		return nil, fmt.Errorf("unknown trace format %q", ext)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read trace %s, got %w", path, err)
+	}
+
+	for index, record := range records {
+		if err := record.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid record %d in trace %s, got %w", index, path, err)
+		}
+	}
+
+	sort.SliceStable(records, func(i, j int) bool {
+		return records[i].Timestamp < records[j].Timestamp
+	})
+	return records, nil
+}
+
+// ReadJSONL reads one JSON encoded record per line, empty lines are skipped.
+func ReadJSONL(r io.Reader) ([]Record, error) {
+	records := []Record{}
+	scanner := bufio.NewScanner(r)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		record := Record{}
+		if err := json.Unmarshal([]byte(text), &record); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		records = append(records, record)
+	}
+	return records, scanner.Err()
+}
+
+// ReadCSV reads records from a CSV file with a header row. The columns are
+// timestamp, skill, mode, trusted, password, party and one ping_<region>
+// column per region. The party column holds the skills of the members
+// separated by ';', members share the pings of the row.
+func ReadCSV(r io.Reader) ([]Record, error) {
+	reader := csv.NewReader(r)
+	rows, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return []Record{}, nil
+	}
+
+	columns := map[string]int{}
+	for index, name := range rows[0] {
+		columns[strings.TrimSpace(name)] = index
+	}
+	for _, required := range []string{"timestamp", "skill", "mode"} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("missing column %q", required)
+		}
+	}
+
+	records := []Record{}
+	for line, row := range rows[1:] {
+		record, err := parseCSVRow(columns, row)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line+2, err)
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+func parseCSVRow(columns map[string]int, row []string) (Record, error) {
+	get := func(name string) string {
+		if index, ok := columns[name]; ok && index < len(row) {
+			return strings.TrimSpace(row[index])
+		}
+		return ""
+	}
+
+	record := Record{
+		Pings:    make(map[string]float64),
+		Mode:     get("mode"),
+		Password: get("password"),
+	}
+
+	var err error
+	if record.Timestamp, err = strconv.ParseFloat(get("timestamp"), 64); err != nil {
+		return record, err
+	}
+	if record.Skill, err = strconv.ParseFloat(get("skill"), 64); err != nil {
+		return record, err
+	}
+	if trusted := get("trusted"); trusted != "" {
+		if record.Trusted, err = strconv.ParseBool(trusted); err != nil {
+			return record, err
+		}
+	}
+	for name := range columns {
+		if region, ok := strings.CutPrefix(name, "ping_"); ok && get(name) != "" {
+			if record.Pings[region], err = strconv.ParseFloat(get(name), 64); err != nil {
+				return record, err
+			}
+		}
+	}
+	if party := get("party"); party != "" {
+		for _, skill := range strings.Split(party, ";") {
+			member := Member{Pings: record.Pings}
+			if member.Skill, err = strconv.ParseFloat(strings.TrimSpace(skill), 64); err != nil {
+				return record, err
+			}
+			record.Party = append(record.Party, member)
+		}
+	}
+	return record, nil
+}
+
+// Replay hands every record to one of the workers at its recorded time,
+// divided by timeScale. A timeScale of 2 replays the trace twice as fast.
+// Every record comes with its own random source derived from r.
+func Replay(ctx context.Context, records []Record, timeScale float64, workers int, r *rand.Rand, work func(ctx context.Context, record Record, r *rand.Rand)) {
+	if timeScale <= 0 {
+		timeScale = 1
+	}
+	if workers < 1 {
+		workers = 1
+	}
+
+	type arrival struct {
+		record Record
+		rng    *rand.Rand
+	}
+	arrivals := make(chan arrival, workers)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for a := range arrivals {
+				work(ctx, a.record, a.rng)
+			}
+		}()
+	}
+	defer func() {
+		close(arrivals)
+		wg.Wait()
+	}()
+
+	start := time.Now()
+	for _, record := range records {
+		at := time.Duration(record.Timestamp / timeScale * float64(time.Second))
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(time.Until(start.Add(at))):
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case arrivals <- arrival{record: record, rng: random.Derive(r)}:
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package trace
+
+import (
+	"strings"
+	"testing"
+
+	utils "sim/internal"
+	"sim/internal/ticket"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadJSONL(t *testing.T) {
+	require := require.New(t)
+
+	input := `{"timestamp": 0.5, "skill": 120, "pings": {"europe": 30, "us": 140}, "mode": "bank_it", "trusted": true}
+
+{"timestamp": 1.5, "skill": 300, "pings": {"europe": 60}, "mode": "quick_cash", "password": "password", "party": [{"skill": 300, "pings": {"europe": 60}}, {"skill": 100, "pings": {"europe": 80}}]}
+`
+	records, err := ReadJSONL(strings.NewReader(input))
+	require.NoError(err)
+	require.Len(records, 2)
+
+	single, err := records[0].ClientData(ticket.AggregateMax)
+	require.NoError(err)
+	require.Equal(utils.GTrustedNameTrue, single.Trusted)
+	require.Equal(1, single.PartySize())
+	require.Equal(140.0, single.RegionData.Pings["us"])
+
+	party, err := records[1].ClientData(ticket.AggregateMax)
+	require.NoError(err)
+	require.Equal(utils.GTrustedNameFalse, party.Trusted)
+	require.Equal("password", party.Password)
+	require.Equal(2, party.PartySize())
+	require.Equal(300.0, party.Skill)
+	require.Equal(80.0, party.RegionData.Pings["europe"])
+}
+
+func TestReadCSV(t *testing.T) {
+	require := require.New(t)
+
+	input := `timestamp,skill,mode,trusted,password,party,ping_europe,ping_us
+0,100,bank_it,true,,,20,150
+2.5,200,tournament_ranked,false,password,200;400,40,90
+`
+	records, err := ReadCSV(strings.NewReader(input))
+	require.NoError(err)
+	require.Len(records, 2)
+
+	require.Equal(Record{
+		Timestamp: 0,
+		Skill:     100,
+		Pings:     map[string]float64{"europe": 20, "us": 150},
+		Mode:      "bank_it",
+		Trusted:   true,
+	}, records[0])
+
+	require.Equal(2.5, records[1].Timestamp)
+	require.Len(records[1].Party, 2)
+	require.Equal(400.0, records[1].Party[1].Skill)
+	require.Equal(90.0, records[1].Party[1].Pings["us"])
+
+	_, err = ReadCSV(strings.NewReader("skill,mode\n1,bank_it\n"))
+	require.Error(err, "Timestamp column is required")
+}
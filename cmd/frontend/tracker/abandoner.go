@@ -0,0 +1,73 @@
+package tracker
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"open-match.dev/open-match/pkg/pb"
+)
+
+// Abandoner cancels tickets that have waited longer than their patience.
+type Abandoner struct {
+	fe      pb.FrontendServiceClient
+	tracker *Tracker
+	// OnAbandoned is called for every ticket that was deleted.
+	OnAbandoned func(record *TicketRecord)
+}
+
+func NewAbandoner(fe pb.FrontendServiceClient, tracker *Tracker) *Abandoner {
+	return &Abandoner{
+		fe:      fe,
+		tracker: tracker,
+	}
+}
+
+// Run checks for impatient tickets on every interval until the context is done.
+func (a *Abandoner) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			a.abandonOverdue(ctx, time.Now())
+		}
+	}
+}
+
+func (a *Abandoner) abandonOverdue(ctx context.Context, now time.Time) {
+	for _, id := range a.tracker.Overdue(now) {
+		if !a.tracker.Claim(id) {
+			continue
+		}
+		// A ticket that got assigned in the meantime is reported by its watch,
+		// deleting it would count matched players as abandoned.
+		t, err := a.fe.GetTicket(ctx, &pb.GetTicketRequest{TicketId: id})
+		if err != nil {
+			log.Printf("Failed to get abandoned ticket %s, got %s", id, err.Error())
+			a.tracker.Unclaim(id)
+			continue
+		}
+		if t.GetAssignment() != nil {
+			a.tracker.Unclaim(id)
+			continue
+		}
+		if _, err := a.fe.DeleteTicket(ctx, &pb.DeleteTicketRequest{TicketId: id}); err != nil {
+			log.Printf("Failed to delete abandoned ticket %s, got %s", id, err.Error())
+			a.tracker.Unclaim(id)
+			continue
+		}
+
+		record, ok := a.tracker.Abandon(id, now)
+		if !ok {
+			// The ticket got assigned while we were deleting it.
+			continue
+		}
+		log.Printf("Ticket %s abandoned after %s", id, now.Sub(record.Created))
+		if a.OnAbandoned != nil {
+			a.OnAbandoned(record)
+		}
+	}
+}
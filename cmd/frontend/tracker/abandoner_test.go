@@ -0,0 +1,43 @@
+package tracker
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"sim/internal/random"
+
+	"github.com/stretchr/testify/require"
+	"open-match.dev/open-match/pkg/pb"
+)
+
+func TestAbandoner(t *testing.T) {
+	require := require.New(t)
+
+	fe := newFakeFrontend()
+	tr := NewTracker(FixedPatience{Duration: time.Minute})
+	abandoner := NewAbandoner(fe, tr)
+	abandoned := []string{}
+	abandoner.OnAbandoned = func(record *TicketRecord) { abandoned = append(abandoned, record.ID) }
+
+	now := time.Now()
+	tr.Add("impatient", testClientData(), now.Add(-2*time.Minute), random.New(1))
+	tr.Add("matched", testClientData(), now.Add(-2*time.Minute), random.New(1))
+	tr.Add("fresh", testClientData(), now, random.New(1))
+	// The match of the ticket was assigned before its watch reported it.
+	fe.tickets["matched"] = &pb.Ticket{Id: "matched", Assignment: &pb.Assignment{Connection: "1.2.3.4:2222"}}
+
+	abandoner.abandonOverdue(context.Background(), now)
+	require.Equal([]string{"impatient"}, fe.deleted)
+	require.Equal([]string{"impatient"}, abandoned)
+	require.False(tr.IsPending("impatient"))
+	require.True(tr.IsPending("fresh"))
+
+	// The assigned ticket is left to its watch and counts as matched.
+	_, ok := tr.Assign("matched", "1.2.3.4:2222", now)
+	require.True(ok)
+	summaries := tr.Summary()
+	require.Len(summaries, 1)
+	require.Equal(1, summaries[0].Abandoned)
+	require.Equal(1, summaries[0].Matched)
+}
@@ -0,0 +1,144 @@
+package tracker
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"sim/cmd/frontend/client"
+	"sim/internal/ticket"
+
+	"open-match.dev/open-match/pkg/pb"
+)
+
+// ExpansionStep widens a ticket once it has waited for After.
+type ExpansionStep struct {
+	After        time.Duration
+	RegionPolicy client.RegionPolicy
+	// SkillWindow is the skill difference the client accepts from this step
+	// on, zero keeps the window of the match profile.
+	SkillWindow float64
+}
+
+// ParseExpansionSteps parses a comma separated list of after/policy/skill
+// steps, for example "30s/relative:100/100,90s/top:3/250". The steps have to be
+// ordered by wait time.
+func ParseExpansionSteps(spec string) ([]ExpansionStep, error) {
+	steps := []ExpansionStep{}
+	if strings.TrimSpace(spec) == "" {
+		return steps, nil
+	}
+
+	for _, entry := range strings.Split(spec, ",") {
+		parts := strings.Split(strings.TrimSpace(entry), "/")
+		if len(parts) != 3 {
+			return nil, fmt.Errorf("expected after/policy/skill, got %q", entry)
+		}
+
+		after, err := time.ParseDuration(parts[0])
+		if err != nil {
+			return nil, fmt.Errorf("invalid wait time in %q, got %w", entry, err)
+		}
+		policy, err := client.ParseRegionPolicy(parts[1])
+		if err != nil {
+			return nil, err
+		}
+		window, err := strconv.ParseFloat(parts[2], 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid skill window in %q, got %w", entry, err)
+		}
+
+		if len(steps) > 0 && after <= steps[len(steps)-1].After {
+			return nil, fmt.Errorf("expansion steps have to be ordered by wait time, got %q", entry)
+		}
+		steps = append(steps, ExpansionStep{After: after, RegionPolicy: policy, SkillWindow: window})
+	}
+	return steps, nil
+}
+
+// Expander re-creates tickets that have waited past an expansion step with a
+// wider set of regions and a wider skill window. The new ticket keeps the
+// original creation time so the match function sees the full wait.
+type Expander struct {
+	fe      pb.FrontendServiceClient
+	tracker *Tracker
+	watcher *Watcher
+	steps   []ExpansionStep
+	// OnDropped is called for tickets that were deleted but could not be
+	// created again.
+	OnDropped func(record *TicketRecord)
+}
+
+func NewExpander(fe pb.FrontendServiceClient, tracker *Tracker, watcher *Watcher, steps []ExpansionStep) *Expander {
+	return &Expander{
+		fe:      fe,
+		tracker: tracker,
+		watcher: watcher,
+		steps:   steps,
+	}
+}
+
+// Run checks for tickets to expand on every interval until the context is done.
+func (e *Expander) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			e.expandDue(ctx, time.Now())
+		}
+	}
+}
+
+func (e *Expander) expandDue(ctx context.Context, now time.Time) {
+	for _, record := range e.tracker.ExpansionDue(now, e.steps) {
+		step := e.steps[record.ClientData.ExpansionLevel]
+
+		clientData := record.ClientData
+		clientData.ExpansionLevel++
+		clientData.RegionPolicy = step.RegionPolicy
+		clientData.SkillWindow = step.SkillWindow
+		clientData.OriginalCreateTime = record.Created
+
+		expanded, err := ticket.MakeTicket(clientData)
+		if err != nil {
+			log.Printf("Failed to encode expanded ticket %s, got %s", record.ID, err.Error())
+			continue
+		}
+
+		// Delete first so the client is never matched twice, if the ticket got
+		// assigned in the meantime there is nothing left to expand.
+		if !e.tracker.Claim(record.ID) {
+			continue
+		}
+		if _, err := e.fe.DeleteTicket(ctx, &pb.DeleteTicketRequest{TicketId: record.ID}); err != nil {
+			log.Printf("Failed to delete ticket %s for expansion, got %s", record.ID, err.Error())
+			e.tracker.Unclaim(record.ID)
+			continue
+		}
+
+		resp, err := e.fe.CreateTicket(ctx, &pb.CreateTicketRequest{Ticket: expanded})
+		if err != nil {
+			log.Printf("Failed to re-create expanded ticket %s, got %s", record.ID, err.Error())
+			if dropped, ok := e.tracker.Drop(record.ID); ok && e.OnDropped != nil {
+				e.OnDropped(dropped)
+			}
+			continue
+		}
+
+		if !e.tracker.Replace(record.ID, resp.GetId(), clientData) {
+			// The old ticket was assigned or abandoned while we were expanding it.
+			if _, err := e.fe.DeleteTicket(ctx, &pb.DeleteTicketRequest{TicketId: resp.GetId()}); err != nil {
+				log.Printf("Failed to delete unneeded expanded ticket %s, got %s", resp.GetId(), err.Error())
+			}
+			continue
+		}
+		e.watcher.Watch(context.Background(), resp.GetId())
+		log.Printf("Expanded ticket %s to %s at level %d after %s", record.ID, resp.GetId(), clientData.ExpansionLevel, now.Sub(record.Created))
+	}
+}
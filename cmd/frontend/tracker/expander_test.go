@@ -0,0 +1,68 @@
+package tracker
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"sim/cmd/frontend/client"
+	"sim/internal/random"
+	"sim/internal/ticket"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestExpander(t *testing.T) {
+	require := require.New(t)
+
+	fe := newFakeFrontend()
+	tr := NewTracker(nil)
+	steps := []ExpansionStep{{After: 30 * time.Second, RegionPolicy: client.TopN{N: 2}, SkillWindow: 100}}
+	expander := NewExpander(fe, tr, NewWatcher(fe, tr, 1), steps)
+
+	now := time.Now()
+	created := now.Add(-time.Minute)
+	clientData := testClientData()
+	clientData.RegionData.Pings["us"] = 120
+	tr.Add("waiting", clientData, created, random.New(1))
+	tr.Add("fresh", clientData, now, random.New(1))
+
+	// The old ticket is deleted before the wider one is created, which keeps
+	// the time the client started queueing.
+	expander.expandDue(context.Background(), now)
+	require.Equal([]string{"waiting"}, fe.deleted)
+	require.Len(fe.created, 1)
+	require.False(tr.IsPending("waiting"))
+	require.True(tr.IsPending("created-a"))
+	require.True(tr.IsPending("fresh"))
+
+	profile, err := ticket.GetPlayerProfile(fe.created[0])
+	require.NoError(err)
+	require.Equal(created.UnixNano(), profile.GetOriginalCreateTime().AsTime().UnixNano())
+	require.EqualValues(1, profile.GetExpansionLevel())
+	require.Equal(100.0, profile.GetSkillWindow())
+	decoded, err := ticket.DecodeTicket(fe.created[0])
+	require.NoError(err)
+	require.Equal(created.UnixNano(), decoded.OriginalCreateTime.UnixNano())
+
+	// The expanded ticket reports the full wait once it is assigned.
+	record, ok := tr.Assign("created-a", "1.2.3.4:2222", now)
+	require.True(ok)
+	require.Equal(time.Minute, record.QueueTime())
+
+	// Expanded tickets are not expanded again until the next step.
+	expander.expandDue(context.Background(), now.Add(time.Hour))
+	require.Len(fe.created, 2, "only the fresh ticket is expanded")
+
+	// A ticket assigned while its replacement is created is not replaced, the
+	// replacement is deleted again.
+	tr.Add("racing", clientData, created, random.New(1))
+	fe.onCreate = func() {
+		_, ok := tr.Assign("racing", "1.2.3.4:2222", now)
+		require.True(ok)
+	}
+	expander.expandDue(context.Background(), now)
+	require.Equal([]string{"waiting", "fresh", "racing", "created-c"}, fe.deleted)
+	require.False(tr.IsPending("created-c"))
+	require.False(tr.IsPending("racing"))
+}
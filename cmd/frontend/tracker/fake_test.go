@@ -0,0 +1,110 @@
+package tracker
+
+import (
+	"context"
+	"io"
+	"sync"
+
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"open-match.dev/open-match/pkg/pb"
+)
+
+// fakeFrontend serves assignments from a map and counts the open streams.
+type fakeFrontend struct {
+	pb.FrontendServiceClient
+	mu          sync.Mutex
+	assignments map[string]chan *pb.Assignment
+	open        int
+	maxOpen     int
+	created     []*pb.Ticket
+	deleted     []string
+	// tickets are returned by GetTicket, unknown tickets have no assignment.
+	tickets map[string]*pb.Ticket
+	// onCreate runs before CreateTicket returns.
+	onCreate func()
+}
+
+func newFakeFrontend() *fakeFrontend {
+	return &fakeFrontend{assignments: map[string]chan *pb.Assignment{}, tickets: map[string]*pb.Ticket{}}
+}
+
+func (f *fakeFrontend) channel(id string) chan *pb.Assignment {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, ok := f.assignments[id]; !ok {
+		f.assignments[id] = make(chan *pb.Assignment, 1)
+	}
+	return f.assignments[id]
+}
+
+func (f *fakeFrontend) WatchAssignments(ctx context.Context, req *pb.WatchAssignmentsRequest, opts ...grpc.CallOption) (pb.FrontendService_WatchAssignmentsClient, error) {
+	f.mu.Lock()
+	f.open++
+	if f.open > f.maxOpen {
+		f.maxOpen = f.open
+	}
+	f.mu.Unlock()
+	return &fakeWatchStream{ctx: ctx, frontend: f, assignments: f.channel(req.GetTicketId())}, nil
+}
+
+func (f *fakeFrontend) CreateTicket(ctx context.Context, req *pb.CreateTicketRequest, opts ...grpc.CallOption) (*pb.Ticket, error) {
+	if f.onCreate != nil {
+		f.onCreate()
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	t := req.GetTicket()
+	t.Id = "created-" + string(rune('a'+len(f.created)))
+	f.created = append(f.created, t)
+	return t, nil
+}
+
+func (f *fakeFrontend) GetTicket(ctx context.Context, req *pb.GetTicketRequest, opts ...grpc.CallOption) (*pb.Ticket, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if t, ok := f.tickets[req.GetTicketId()]; ok {
+		return t, nil
+	}
+	return &pb.Ticket{Id: req.GetTicketId()}, nil
+}
+
+func (f *fakeFrontend) DeleteTicket(ctx context.Context, req *pb.DeleteTicketRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.deleted = append(f.deleted, req.GetTicketId())
+	return &emptypb.Empty{}, nil
+}
+
+type fakeWatchStream struct {
+	grpc.ClientStream
+	ctx         context.Context
+	frontend    *fakeFrontend
+	assignments chan *pb.Assignment
+	closed      bool
+}
+
+func (s *fakeWatchStream) Recv() (*pb.WatchAssignmentsResponse, error) {
+	select {
+	case assignment, ok := <-s.assignments:
+		if !ok {
+			s.close()
+			return nil, io.EOF
+		}
+		s.close()
+		return &pb.WatchAssignmentsResponse{Assignment: assignment}, nil
+	case <-s.ctx.Done():
+		s.close()
+		return nil, s.ctx.Err()
+	}
+}
+
+func (s *fakeWatchStream) close() {
+	if s.closed {
+		return
+	}
+	s.closed = true
+	s.frontend.mu.Lock()
+	s.frontend.open--
+	s.frontend.mu.Unlock()
+}
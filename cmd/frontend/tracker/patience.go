@@ -0,0 +1,69 @@
+package tracker
+
+import (
+	"fmt"
+	"math/rand"
+	"time"
+
+	"sim/internal/ticket"
+)
+
+// PatienceModel decides how long a client is willing to wait in the queue
+// before it cancels its ticket. A zero duration means the client never gives up.
+type PatienceModel interface {
+	Patience(r *rand.Rand, clientData ticket.ClientMatchmakingData) time.Duration
+}
+
+// FixedPatience gives every client the same patience.
+type FixedPatience struct {
+	Duration time.Duration
+}
+
+func (p FixedPatience) Patience(*rand.Rand, ticket.ClientMatchmakingData) time.Duration {
+	return p.Duration
+}
+
+// ExponentialPatience draws the patience of every ticket from an exponential
+// distribution with the given mean.
+type ExponentialPatience struct {
+	Mean time.Duration
+}
+
+func (p ExponentialPatience) Patience(r *rand.Rand, _ ticket.ClientMatchmakingData) time.Duration {
+	return time.Duration(r.ExpFloat64() * float64(p.Mean))
+}
+
+// PlayerPatience looks up the patience of the persistent player behind the
+// ticket and uses Fallback for anonymous clients.
+type PlayerPatience struct {
+	Lookup   func(playerID string) (time.Duration, bool)
+	Fallback PatienceModel
+}
+
+func (p PlayerPatience) Patience(r *rand.Rand, clientData ticket.ClientMatchmakingData) time.Duration {
+	if p.Lookup != nil {
+		if patience, ok := p.Lookup(clientData.PlayerID); ok {
+			return patience
+		}
+	}
+	if p.Fallback != nil {
+		return p.Fallback.Patience(r, clientData)
+	}
+	return 0
+}
+
+// NewPatienceModel creates a patience model by name. Lookup is only used by
+// the "player" model.
+func NewPatienceModel(name string, mean time.Duration, lookup func(playerID string) (time.Duration, bool)) (PatienceModel, error) {
+	switch name {
+	case "", "none":
+		return nil, nil
+	case "fixed":
+		return FixedPatience{Duration: mean}, nil
+	case "exponential":
+		return ExponentialPatience{Mean: mean}, nil
+	case "player":
+		return PlayerPatience{Lookup: lookup, Fallback: ExponentialPatience{Mean: mean}}, nil
+	}
+	return nil, fmt.Errorf("unknown patience model %q", name)
+}
@@ -0,0 +1,353 @@
+package tracker
+
+import (
+	"log"
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+
+	"sim/cmd/frontend/client"
+	utils "sim/internal"
+	"sim/internal/scenario"
+	"sim/internal/ticket"
+)
+
+// GroupKey identifies the match profile a ticket queues in, abandonment and
+// queue times are reported per profile.
+type GroupKey struct {
+	// Password tickets queue in the profile of their lobby, the other fields
+	// are empty for them.
+	Password string
+	Region   string
+	GameMode string
+	SkillMin float64
+	SkillMax float64
+	Trusted  bool
+	Beginner bool
+}
+
+// String returns the name the director gives the profile, for example
+// europe.bank_it.skill_0-500.trusted.open.
+func (k GroupKey) String() string {
+	if k.Password != "" {
+		return scenario.PasswordProfileName(k.Password)
+	}
+	return scenario.ProfileName(k.Region, k.GameMode, k.SkillMin, k.SkillMax, k.Trusted, k.Beginner)
+}
+
+var (
+	// GScenario is the scenario the director creates profiles for, tickets
+	// are grouped by its skill buckets and queue splits.
+	GScenario = scenario.Default()
+)
+
+// TicketRecord holds everything we learn about a ticket during its lifetime.
+type TicketRecord struct {
+	ID         string
+	Group      GroupKey
+	ClientData ticket.ClientMatchmakingData
+	Created    time.Time
+	Assigned   time.Time
+	Connection string
+	// Patience is how long the client waits before cancelling, zero waits forever.
+	Patience time.Duration
+	// claimed is set while the ticket is deleted on purpose.
+	claimed bool
+}
+
+// QueueTime returns how long the ticket waited before being assigned.
+func (r *TicketRecord) QueueTime() time.Duration {
+	return r.Assigned.Sub(r.Created)
+}
+
+// GroupSummary holds queue time percentiles for one group of tickets.
+type GroupSummary struct {
+	Group     GroupKey
+	Matched   int
+	Pending   int
+	Abandoned int
+	// Failed counts tickets whose assignment could not be watched, for
+	// example because they timed out.
+	Failed int
+	// AbandonRate is the share of finished tickets that were abandoned.
+	AbandonRate float64
+	P50         time.Duration
+	P90         time.Duration
+	P99         time.Duration
+	MaxQueue    time.Duration
+}
+
+// Tracker keeps track of created tickets and how long they took to be matched.
+type Tracker struct {
+	mu         sync.Mutex
+	patience   PatienceModel
+	pending    map[string]*TicketRecord
+	queueTimes map[GroupKey][]time.Duration
+	abandoned  map[GroupKey]int
+	failed     map[GroupKey]int
+}
+
+// NewTracker creates a tracker, patience may be nil if clients never give up.
+func NewTracker(patience PatienceModel) *Tracker {
+	return &Tracker{
+		patience:   patience,
+		pending:    make(map[string]*TicketRecord),
+		queueTimes: make(map[GroupKey][]time.Duration),
+		abandoned:  make(map[GroupKey]int),
+		failed:     make(map[GroupKey]int),
+	}
+}
+
+// GroupFor returns the profile a client queues in, using its best region and
+// the skill bucket its skill falls into. Skills outside of the buckets count
+// towards the closest one.
+func GroupFor(clientData ticket.ClientMatchmakingData) GroupKey {
+	if clientData.Password != "" {
+		return GroupKey{Password: clientData.Password}
+	}
+
+	region := ""
+	if regions := client.GetDesiredRegions(clientData.RegionData.Pings); len(regions) > 0 {
+		region = regions[0].Region
+	}
+	key := GroupKey{
+		Region:   region,
+		GameMode: clientData.GameMode,
+	}
+	if mode, ok := GScenario.Mode(clientData.GameMode); ok {
+		key.SkillMin, key.SkillMax = mode.Bucket(clientData.Skill)
+		key.Trusted = mode.TrustedSplit && clientData.Trusted == utils.GTrustedNameTrue
+		key.Beginner = mode.BeginnerSplit && clientData.Beginner
+	}
+	return key
+}
+
+// Add starts tracking a freshly created ticket, r is used to draw its patience.
+func (t *Tracker) Add(id string, clientData ticket.ClientMatchmakingData, created time.Time, r *rand.Rand) *TicketRecord {
+	record := &TicketRecord{
+		ID:         id,
+		Group:      GroupFor(clientData),
+		ClientData: clientData,
+		Created:    created,
+	}
+	if t.patience != nil {
+		record.Patience = t.patience.Patience(r, clientData)
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.pending[id] = record
+	return record
+}
+
+// Assign records the assignment of a pending ticket and stops tracking it.
+// Returns false if the ticket is not tracked anymore.
+func (t *Tracker) Assign(id string, connection string, assigned time.Time) (*TicketRecord, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	record, ok := t.pending[id]
+	if !ok {
+		return nil, false
+	}
+	delete(t.pending, id)
+
+	record.Assigned = assigned
+	record.Connection = connection
+	t.queueTimes[record.Group] = append(t.queueTimes[record.Group], record.QueueTime())
+	return record, true
+}
+
+// Overdue returns the IDs of pending tickets that have run out of patience.
+func (t *Tracker) Overdue(now time.Time) []string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	ids := []string{}
+	for id, record := range t.pending {
+		if record.Patience > 0 && now.Sub(record.Created) >= record.Patience {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// Abandon records that a pending ticket was cancelled and stops tracking it.
+// Returns false if the ticket is not tracked anymore.
+func (t *Tracker) Abandon(id string, now time.Time) (*TicketRecord, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	record, ok := t.pending[id]
+	if !ok {
+		return nil, false
+	}
+	delete(t.pending, id)
+
+	t.abandoned[record.Group]++
+	return record, true
+}
+
+// ExpansionDue returns copies of the pending tickets that have waited past
+// their next expansion step.
+func (t *Tracker) ExpansionDue(now time.Time, steps []ExpansionStep) []TicketRecord {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	due := []TicketRecord{}
+	for _, record := range t.pending {
+		level := record.ClientData.ExpansionLevel
+		if level < len(steps) && now.Sub(record.Created) >= steps[level].After {
+			due = append(due, *record)
+		}
+	}
+	return due
+}
+
+// Replace moves a pending ticket to the ID of the ticket that replaced it. The
+// creation time and patience are kept, a claim on the old ticket ends with it.
+// Returns false if the old ticket is not tracked anymore.
+func (t *Tracker) Replace(oldID string, newID string, clientData ticket.ClientMatchmakingData) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	record, ok := t.pending[oldID]
+	if !ok {
+		return false
+	}
+	delete(t.pending, oldID)
+
+	record.ID = newID
+	record.ClientData = clientData
+	record.claimed = false
+	t.pending[newID] = record
+	return true
+}
+
+// Drop stops tracking a pending ticket without counting it as matched or
+// abandoned.
+func (t *Tracker) Drop(id string) (*TicketRecord, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	record, ok := t.pending[id]
+	if ok {
+		delete(t.pending, id)
+	}
+	return record, ok
+}
+
+// Claim marks a pending ticket as being deleted on purpose, for example to
+// abandon or expand it, so that its ending watch is not taken for a failure.
+// Returns false if the ticket is not tracked anymore.
+func (t *Tracker) Claim(id string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	record, ok := t.pending[id]
+	if ok {
+		record.claimed = true
+	}
+	return ok
+}
+
+// Unclaim undoes Claim when the ticket was not deleted after all.
+func (t *Tracker) Unclaim(id string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if record, ok := t.pending[id]; ok {
+		record.claimed = false
+	}
+}
+
+// Fail stops tracking a pending ticket whose assignment can not be watched
+// anymore, unless it is claimed. Returns false if the ticket is not tracked
+// or claimed.
+func (t *Tracker) Fail(id string) (*TicketRecord, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	record, ok := t.pending[id]
+	if !ok || record.claimed {
+		return nil, false
+	}
+	delete(t.pending, id)
+	t.failed[record.Group]++
+	return record, true
+}
+
+// IsPending returns whether the ticket is still waiting for an assignment.
+func (t *Tracker) IsPending(id string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	_, ok := t.pending[id]
+	return ok
+}
+
+// Summary returns queue time percentiles per group, sorted by group name.
+func (t *Tracker) Summary() []GroupSummary {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	summaries := map[GroupKey]*GroupSummary{}
+	get := func(key GroupKey) *GroupSummary {
+		if s, ok := summaries[key]; ok {
+			return s
+		}
+		s := &GroupSummary{Group: key}
+		summaries[key] = s
+		return s
+	}
+
+	for key, times := range t.queueTimes {
+		sorted := append([]time.Duration(nil), times...)
+		sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+		s := get(key)
+		s.Matched = len(sorted)
+		s.P50 = percentile(sorted, 0.50)
+		s.P90 = percentile(sorted, 0.90)
+		s.P99 = percentile(sorted, 0.99)
+		s.MaxQueue = sorted[len(sorted)-1]
+	}
+	for _, record := range t.pending {
+		get(record.Group).Pending++
+	}
+	for key, abandoned := range t.abandoned {
+		s := get(key)
+		s.Abandoned = abandoned
+		s.AbandonRate = float64(abandoned) / float64(abandoned+s.Matched)
+	}
+	for key, failed := range t.failed {
+		get(key).Failed = failed
+	}
+
+	returnData := []GroupSummary{}
+	for _, s := range summaries {
+		returnData = append(returnData, *s)
+	}
+	sort.Slice(returnData, func(i, j int) bool {
+		return returnData[i].Group.String() < returnData[j].Group.String()
+	})
+	return returnData
+}
+
+// LogSummary prints the current queue time summary.
+func (t *Tracker) LogSummary() {
+	for _, s := range t.Summary() {
+		log.Printf("Queue time %s: matched %d pending %d abandoned %d (%.1f%%) failed %d p50 %s p90 %s p99 %s max %s",
+			s.Group, s.Matched, s.Pending, s.Abandoned, s.AbandonRate*100, s.Failed, s.P50, s.P90, s.P99, s.MaxQueue)
+	}
+}
+
+// percentile expects sorted input.
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	index := int(p * float64(len(sorted)-1))
+	return sorted[index]
+}
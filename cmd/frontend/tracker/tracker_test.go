@@ -0,0 +1,128 @@
+package tracker
+
+import (
+	"testing"
+	"time"
+
+	"sim/cmd/frontend/client"
+	"sim/internal/random"
+	"sim/internal/scenario"
+	"sim/internal/ticket"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueueTimeSummary(t *testing.T) {
+	require := require.New(t)
+
+	tr := NewTracker(nil)
+	start := time.Now()
+	clientData := ticket.ClientMatchmakingData{
+		RegionData: client.ClientRegionData{
+			Pings: map[string]float64{"europe": 20, "us": 150},
+		},
+		Trusted:  "trusted_true",
+		GameMode: "bank_it",
+	}
+
+	for i := 0; i < 10; i++ {
+		tr.Add(string(rune('a'+i)), clientData, start, random.New(1))
+	}
+	for i := 0; i < 9; i++ {
+		_, ok := tr.Assign(string(rune('a'+i)), "1.2.3.4:2222", start.Add(time.Duration(i+1)*time.Second))
+		require.True(ok)
+	}
+	_, ok := tr.Assign("unknown", "1.2.3.4:2222", start)
+	require.False(ok, "Unknown tickets are not assigned")
+
+	summaries := tr.Summary()
+	require.Len(summaries, 1)
+	s := summaries[0]
+	require.Equal(GroupKey{GameMode: "bank_it", Region: "europe", SkillMin: 0, SkillMax: 500, Trusted: true}, s.Group)
+	require.Equal("europe.bank_it.skill_0-500.trusted.open", s.Group.String())
+	require.Equal(9, s.Matched)
+	require.Equal(1, s.Pending)
+	require.Equal(5*time.Second, s.P50)
+	require.Equal(9*time.Second, s.MaxQueue)
+}
+
+func TestAbandonment(t *testing.T) {
+	require := require.New(t)
+
+	tr := NewTracker(FixedPatience{Duration: time.Minute})
+	start := time.Now()
+	clientData := ticket.ClientMatchmakingData{
+		RegionData: client.ClientRegionData{
+			Pings: map[string]float64{"europe": 20},
+		},
+		GameMode: "bank_it",
+	}
+	tr.Add("a", clientData, start, random.New(1))
+	tr.Add("b", clientData, start.Add(30*time.Second), random.New(1))
+	tr.Add("c", clientData, start, random.New(1))
+
+	_, ok := tr.Assign("c", "1.2.3.4:2222", start.Add(10*time.Second))
+	require.True(ok)
+
+	overdue := tr.Overdue(start.Add(time.Minute))
+	require.Equal([]string{"a"}, overdue)
+
+	_, ok = tr.Abandon("a", start.Add(time.Minute))
+	require.True(ok)
+	_, ok = tr.Abandon("c", start.Add(time.Minute))
+	require.False(ok, "Assigned tickets can not be abandoned")
+
+	summaries := tr.Summary()
+	require.Len(summaries, 1)
+	require.Equal(1, summaries[0].Abandoned)
+	require.Equal(1, summaries[0].Pending)
+	require.InDelta(0.5, summaries[0].AbandonRate, 0.0001)
+}
+
+func TestReplaceClaimed(t *testing.T) {
+	require := require.New(t)
+
+	tr := NewTracker(nil)
+	clientData := ticket.ClientMatchmakingData{GameMode: "bank_it"}
+	tr.Add("old", clientData, time.Now(), random.New(1))
+
+	// A claimed ticket does not fail, the ticket replacing it does.
+	require.True(tr.Claim("old"))
+	_, ok := tr.Fail("old")
+	require.False(ok)
+	require.True(tr.Replace("old", "new", clientData))
+	_, ok = tr.Fail("new")
+	require.True(ok)
+	require.False(tr.IsPending("new"))
+}
+
+func TestGroupFor(t *testing.T) {
+	require := require.New(t)
+
+	clientData := ticket.ClientMatchmakingData{
+		RegionData: client.ClientRegionData{
+			Pings: map[string]float64{"europe": 80, "us": 20},
+		},
+		Trusted:  "trusted_false",
+		GameMode: "quick_cash",
+		Skill:    500,
+		Beginner: true,
+	}
+	require.Equal("us.quick_cash.skill_500-1500.untrusted.open", GroupFor(clientData).String())
+
+	clientData.Skill = 4000
+	require.Equal("us.quick_cash.skill_500-1500.untrusted.open", GroupFor(clientData).String())
+	clientData.Skill = -10
+	require.Equal("us.quick_cash.skill_0-500.untrusted.open", GroupFor(clientData).String())
+
+	// The groups follow the buckets and splits of the scenario per mode.
+	defer func(config scenario.Config) { GScenario = config }(GScenario)
+	GScenario.Modes = []scenario.ModeConfig{{Name: "quick_cash", SkillBuckets: []float64{-100, 0, 250}, TrustedSplit: true, BeginnerSplit: true}}
+	require.Equal("us.quick_cash.skill_-100-0.untrusted.beginner", GroupFor(clientData).String())
+	clientData.Trusted = "trusted_true"
+	clientData.Skill = 100
+	require.Equal("us.quick_cash.skill_0-250.trusted.beginner", GroupFor(clientData).String())
+
+	clientData.Password = "secret"
+	require.Equal("password.secret", GroupFor(clientData).String())
+}
@@ -0,0 +1,142 @@
+package tracker
+
+import (
+	"context"
+	"io"
+	"log"
+	"sync"
+	"time"
+
+	utils "sim/internal"
+
+	"open-match.dev/open-match/pkg/pb"
+)
+
+// Watcher follows ticket assignments through WatchAssignments. The tickets
+// are queued and watched by a fixed number of workers, so neither the open
+// streams nor the goroutines grow with the number of queueing tickets.
+type Watcher struct {
+	fe      pb.FrontendServiceClient
+	tracker *Tracker
+	// OnAssigned is called for every ticket that received an assignment.
+	OnAssigned func(record *TicketRecord)
+	// OnFailed is called for every ticket whose stream ended without an
+	// assignment while the ticket was still pending and not claimed. The
+	// tracker does not track these tickets anymore.
+	OnFailed func(record *TicketRecord, err error)
+	// Timeout fails tickets that were not assigned this long after they were
+	// queued for watching, zero waits forever.
+	Timeout time.Duration
+
+	mu    sync.Mutex
+	ready *sync.Cond
+	queue []watchRequest
+}
+
+type watchRequest struct {
+	ctx    context.Context
+	id     string
+	queued time.Time
+}
+
+// NewWatcher starts maxConcurrent workers that live as long as the process.
+func NewWatcher(fe pb.FrontendServiceClient, tracker *Tracker, maxConcurrent int) *Watcher {
+	if maxConcurrent < 1 {
+		maxConcurrent = 1
+	}
+	w := &Watcher{
+		fe:      fe,
+		tracker: tracker,
+	}
+	w.ready = sync.NewCond(&w.mu)
+	for i := 0; i < maxConcurrent; i++ {
+		go w.work()
+	}
+	return w
+}
+
+// Watch queues the ticket to be watched until it is assigned.
+func (w *Watcher) Watch(ctx context.Context, id string) {
+	w.mu.Lock()
+	w.queue = append(w.queue, watchRequest{ctx: ctx, id: id, queued: time.Now()})
+	w.mu.Unlock()
+	w.ready.Signal()
+}
+
+// Queued returns the number of tickets waiting for a worker.
+func (w *Watcher) Queued() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return len(w.queue)
+}
+
+func (w *Watcher) work() {
+	for {
+		w.mu.Lock()
+		for len(w.queue) == 0 {
+			w.ready.Wait()
+		}
+		req := w.queue[0]
+		w.queue[0] = watchRequest{}
+		w.queue = w.queue[1:]
+		w.mu.Unlock()
+
+		ctx, cancel := req.ctx, context.CancelFunc(func() {})
+		if w.Timeout > 0 {
+			ctx, cancel = context.WithDeadline(ctx, req.queued.Add(w.Timeout))
+		}
+		err := w.watch(ctx, req.id)
+		cancel()
+
+		// Tickets that were deleted on purpose are claimed and end their
+		// stream with an error.
+		if err == nil {
+			continue
+		}
+		if record, ok := w.tracker.Fail(req.id); ok {
+			log.Printf("Failed to watch assignments for ticket %s, got %s", req.id, err.Error())
+			if w.OnFailed != nil {
+				w.OnFailed(record, err)
+			}
+		}
+	}
+}
+
+func (w *Watcher) watch(ctx context.Context, id string) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	stream, err := w.fe.WatchAssignments(ctx, &pb.WatchAssignmentsRequest{TicketId: id})
+	if err != nil {
+		return err
+	}
+
+	for {
+		resp, err := stream.Recv()
+		if err == io.EOF {
+			return io.ErrUnexpectedEOF
+		}
+		if err != nil {
+			return err
+		}
+
+		connection := resp.GetAssignment().GetConnection()
+		if connection == "" {
+			continue
+		}
+
+		if record, ok := w.tracker.Assign(id, connection, assignedAt(resp.GetAssignment())); ok && w.OnAssigned != nil {
+			w.OnAssigned(record)
+		}
+		return nil
+	}
+}
+
+// assignedAt returns when the director assigned the ticket, assignments
+// without a time fall back to the time they were received.
+func assignedAt(assignment *pb.Assignment) time.Time {
+	if at, err := utils.GetMessage(assignment.GetExtensions(), utils.AssignmentTime); err == nil {
+		return at.AsTime()
+	}
+	return time.Now()
+}
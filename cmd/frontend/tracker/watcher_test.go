@@ -0,0 +1,91 @@
+package tracker
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"sim/cmd/frontend/client"
+	utils "sim/internal"
+	"sim/internal/random"
+	"sim/internal/ticket"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"open-match.dev/open-match/pkg/pb"
+)
+
+func testClientData() ticket.ClientMatchmakingData {
+	return ticket.ClientMatchmakingData{
+		RegionData: client.ClientRegionData{
+			Pings: map[string]float64{"europe": 20},
+		},
+		GameMode: "bank_it",
+	}
+}
+
+func TestWatcher(t *testing.T) {
+	require := require.New(t)
+
+	fe := newFakeFrontend()
+	tr := NewTracker(nil)
+	watcher := NewWatcher(fe, tr, 2)
+	var mu sync.Mutex
+	assigned := map[string]*TicketRecord{}
+	watcher.OnAssigned = func(record *TicketRecord) {
+		mu.Lock()
+		defer mu.Unlock()
+		assigned[record.ID] = record
+	}
+	failed := make(chan string, 1)
+	watcher.OnFailed = func(record *TicketRecord, err error) {
+		failed <- record.ID
+	}
+
+	start := time.Now().Add(-time.Minute)
+	for i := 0; i < 5; i++ {
+		id := fmt.Sprintf("ticket-%d", i)
+		tr.Add(id, testClientData(), start, random.New(1))
+		watcher.Watch(context.Background(), id)
+	}
+
+	// Only two tickets are watched at a time, the rest wait in the queue.
+	require.Eventually(func() bool { return watcher.Queued() == 3 }, time.Second, time.Millisecond)
+
+	// The assignment time comes from the director, not from when the stream
+	// was opened.
+	assignedAt := start.Add(10 * time.Second)
+	for i := 0; i < 4; i++ {
+		assignment := &pb.Assignment{Connection: "1.2.3.4:2222", Extensions: map[string]*anypb.Any{}}
+		utils.SetMessage(assignment.Extensions, utils.AssignmentTime, timestamppb.New(assignedAt))
+		fe.channel(fmt.Sprintf("ticket-%d", i)) <- assignment
+	}
+	close(fe.channel("ticket-4"))
+
+	require.Equal("ticket-4", <-failed)
+	require.Eventually(func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(assigned) == 4
+	}, time.Second, time.Millisecond)
+	for _, record := range assigned {
+		require.Equal(10*time.Second, record.QueueTime())
+	}
+	require.Equal(2, fe.maxOpen)
+	require.False(tr.IsPending("ticket-4"))
+	require.Equal(1, tr.Summary()[0].Failed)
+
+	// Claimed tickets are deleted on purpose and do not fail, unassigned
+	// tickets fail once they time out.
+	watcher.Timeout = 20 * time.Millisecond
+	tr.Add("claimed", testClientData(), start, random.New(1))
+	require.True(tr.Claim("claimed"))
+	watcher.Watch(context.Background(), "claimed")
+	tr.Add("late", testClientData(), start, random.New(1))
+	watcher.Watch(context.Background(), "late")
+	require.Equal("late", <-failed)
+	require.True(tr.IsPending("claimed"))
+}
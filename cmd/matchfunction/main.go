@@ -20,6 +20,10 @@
 package main
 
 import (
+	"flag"
+	"log"
+	"strings"
+
 	"sim/cmd/matchfunction/mmf"
 )
 
@@ -33,5 +37,26 @@ const (
 )
 
 func main() {
+	partition := flag.String("partition", string(mmf.PartitionGreedy), "how the skill_window strategy splits tickets into matches: greedy, or min-spread which forms the most matches from windows of consecutive tickets with the highest summed score")
+	flag.StringVar(&mmf.GDefaultStrategy, "default-strategy", mmf.GDefaultStrategy, "strategy for profiles that do not name one: "+strings.Join(mmf.StrategyNames(), ", "))
+	scoreWeights := flag.String("score-weights", "", "comma separated component=weight pairs for the match score, components are skill, latency and wait")
+	flag.Parse()
+
+	mode, err := mmf.ParsePartitionMode(*partition)
+	if err != nil {
+		log.Fatalf("Invalid partition mode, got %s", err.Error())
+	}
+	mmf.GPartitionMode = mode
+
+	weights, err := mmf.ParseScoreWeights(*scoreWeights)
+	if err != nil {
+		log.Fatalf("Invalid score weights, got %s", err.Error())
+	}
+	mmf.GScoreWeights = weights
+
+	if _, err := mmf.NewStrategy(mmf.GDefaultStrategy, nil); err != nil {
+		log.Fatalf("Invalid default strategy, got %s", err.Error())
+	}
+
 	mmf.Start(queryServiceAddress, serverPort)
 }
@@ -0,0 +1,171 @@
+package mmf
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"time"
+
+	"sim/internal/backfill"
+	"sim/internal/ticket"
+	simproto "sim/proto"
+
+	"google.golang.org/protobuf/proto"
+	"open-match.dev/open-match/pkg/pb"
+)
+
+// fillBackfills hands waiting tickets to the open slots of running games, the
+// oldest backfill first. A ticket joins a game if its own skill window accepts
+// the average skill of the game. It returns the tickets that were not used.
+func fillBackfills(backfills []*pb.Backfill, tickets []*pb.Ticket, profile ProfileData) ([]*pb.Match, []*pb.Ticket, error) {
+	sort.SliceStable(backfills, func(i, j int) bool {
+		return backfills[i].GetCreateTime().AsTime().Before(backfills[j].GetCreateTime().AsTime())
+	})
+
+	now := time.Now()
+	matches := []*pb.Match{}
+	remaining := tickets
+	for _, b := range backfills {
+		state, err := backfill.GetState(b)
+		if err != nil {
+			log.Printf("Failed to read backfill, got %s", err.Error())
+			continue
+		}
+
+		taken, rest := []*pb.Ticket{}, []*pb.Ticket{}
+		players := 0
+		for _, t := range remaining {
+			size := ticket.GetPartySizeFromTicket(t)
+			if players+size > int(state.GetOpenSlots()) {
+				rest = append(rest, t)
+				continue
+			}
+			window, err := ticketSkillWindow(t, profile, now)
+			if err != nil {
+				return nil, nil, fmt.Errorf("ticket %s, got %w", t.GetId(), err)
+			}
+			if math.Abs(ticket.GetSkillFromTicket(t)-state.GetSkill()) >= window {
+				rest = append(rest, t)
+				continue
+			}
+			taken = append(taken, t)
+			players += size
+		}
+		if len(taken) == 0 {
+			continue
+		}
+		remaining = rest
+
+		match, err := newMatch(taken, profile, len(matches))
+		if err != nil {
+			return nil, nil, err
+		}
+		match.MatchId = fmt.Sprintf("%s-backfill-%s", match.MatchId, b.GetId())
+
+		inGame := float64(state.GetMaxPlayers() - state.GetOpenSlots())
+		updated := proto.Clone(b).(*pb.Backfill)
+		backfill.SetState(updated, &simproto.BackfillState{
+			MaxPlayers: state.GetMaxPlayers(),
+			OpenSlots:  state.GetOpenSlots() - int32(players),
+			Skill:      (inGame*state.GetSkill() + playerSkill(taken)) / (inGame + float64(players)),
+		})
+		match.Backfill = updated
+		matches = append(matches, match)
+	}
+	return matches, remaining, nil
+}
+
+// partialMatches starts games with a backfill for skill sorted tickets that
+// could not fill a match, as long as enough players accept each other. Tickets
+// that have not waited the profile's BackfillMinWait yet keep waiting for a
+// full match.
+func partialMatches(sorted []*pb.Ticket, pool *pb.Pool, profile ProfileData) ([]*pb.Match, error) {
+	minPlayers := profile.BackfillMinPlayers
+	if minPlayers <= 0 {
+		minPlayers = int(math.Max(1, float64(profile.MaxPlayer/2)))
+	}
+
+	now := time.Now()
+	waited := []*pb.Ticket{}
+	for _, t := range sorted {
+		wait, err := ticket.GetWaitTimeFromTicket(t, now)
+		if err != nil {
+			return nil, err
+		}
+		if wait >= profile.BackfillMinWait {
+			waited = append(waited, t)
+		}
+	}
+	sorted = waited
+
+	matches := []*pb.Match{}
+	for start := 0; start < len(sorted); {
+		group := []*pb.Ticket{}
+		players := 0
+		for _, t := range sorted[start:] {
+			size := ticket.GetPartySizeFromTicket(t)
+			// A game with a backfill needs at least one open slot.
+			if players+size >= profile.MaxPlayer {
+				break
+			}
+			// The teams of a partial match are only known once it is full.
+			valid, err := compatible(append(group[:len(group):len(group)], t), profile)
+			if err != nil {
+				return nil, err
+			}
+			if !valid {
+				break
+			}
+			group = append(group, t)
+			players += size
+		}
+
+		if len(group) == 0 || players < minPlayers {
+			start++
+			continue
+		}
+		start += len(group)
+
+		match, err := newMatch(group, profile, len(matches))
+		if err != nil {
+			return nil, err
+		}
+		match.MatchId = fmt.Sprintf("%s-partial", match.MatchId)
+		match.Backfill = backfill.New(pool, &simproto.BackfillState{
+			MaxPlayers: int32(profile.MaxPlayer),
+			OpenSlots:  int32(profile.MaxPlayer - players),
+			Skill:      playerSkill(group) / float64(players),
+		})
+		match.AllocateGameserver = true
+		matches = append(matches, match)
+	}
+	return matches, nil
+}
+
+// playerSkill returns the summed skill of every player on the tickets.
+func playerSkill(tickets []*pb.Ticket) float64 {
+	skill := 0.0
+	for _, t := range tickets {
+		skill += ticket.GetSkillFromTicket(t) * float64(ticket.GetPartySizeFromTicket(t))
+	}
+	return skill
+}
+
+// unmatched returns the tickets that are in none of the matches.
+func unmatched(tickets []*pb.Ticket, matches []*pb.Match) []*pb.Ticket {
+	matched := map[string]bool{}
+	for _, match := range matches {
+		for _, t := range match.GetTickets() {
+			matched[t.GetId()] = true
+		}
+	}
+
+	remaining := []*pb.Ticket{}
+	for _, t := range tickets {
+		if !matched[t.GetId()] {
+			remaining = append(remaining, t)
+		}
+	}
+	return remaining
+}
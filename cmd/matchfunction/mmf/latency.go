@@ -0,0 +1,64 @@
+package mmf
+
+import (
+	"errors"
+	"math"
+
+	"sim/internal/ticket"
+
+	"open-match.dev/open-match/pkg/pb"
+)
+
+// gateLatency drops the tickets that may not play in the region of the
+// profile: tickets without a ping towards it and tickets above its max ping,
+// unless it is their best region. Profiles without a region accept every
+// ticket.
+func gateLatency(poolTickets map[string][]*pb.Ticket, profile ProfileData) (map[string][]*pb.Ticket, error) {
+	if profile.Region == "" {
+		return poolTickets, nil
+	}
+
+	maxPing := unlimited(profile.MaxPing)
+	gated := make(map[string][]*pb.Ticket, len(poolTickets))
+	for pool, tickets := range poolTickets {
+		gated[pool] = []*pb.Ticket{}
+		for _, t := range tickets {
+			latency, err := ticket.GetLatencyFromTicket(t, profile.Region)
+			if errors.Is(err, ticket.ErrNoPing) {
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			// A client is never locked out of the region that suits it best.
+			best, err := ticket.GetBestRegionFromTicket(t)
+			if err != nil {
+				return nil, err
+			}
+			if latency <= maxPing || best == profile.Region {
+				gated[pool] = append(gated[pool], t)
+			}
+		}
+	}
+	return gated, nil
+}
+
+// withinPingSpread returns whether the pings of the match towards the profile
+// region differ by no more than the profile allows. The pings of tickets let
+// through by gateLatency for their best region count in full.
+func withinPingSpread(mt []*pb.Ticket, profile ProfileData) (bool, error) {
+	if profile.Region == "" || profile.MaxPingSpread <= 0 {
+		return true, nil
+	}
+
+	lowest, highest := math.Inf(1), math.Inf(-1)
+	for _, t := range mt {
+		latency, err := ticket.GetLatencyFromTicket(t, profile.Region)
+		if err != nil {
+			return false, err
+		}
+		lowest = math.Min(lowest, latency)
+		highest = math.Max(highest, latency)
+	}
+	return highest-lowest <= profile.MaxPingSpread, nil
+}
@@ -17,18 +17,21 @@ package mmf
 import (
 	"fmt"
 	"log"
+	"math"
 	"sort"
 	"time"
 
 	"sim/internal/ticket"
 
 	utils "sim/internal"
+	simproto "sim/proto"
 
 	// Uncomment if following the tutorial
 	// "fmt"
 	// "time"
 
 	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/durationpb"
 	"open-match.dev/open-match/pkg/matchfunction"
 	"open-match.dev/open-match/pkg/pb"
 )
@@ -37,23 +40,30 @@ const (
 	matchName = "basic-matchfunction"
 )
 
-type CalculationMode int
-
-const (
-	All CalculationMode = iota
-	Skill
-)
-
 type ProfileData struct {
 	ProfileName string
 	Region      string
 	MaxPlayer   int
-	MaxPing     int
-	MaxSkill    int
+	// MaxPing is the highest ping towards Region and MaxPingSpread the largest
+	// ping difference within a match, zero does not limit them.
+	MaxPing       float64
+	MaxPingSpread float64
+	MaxSkill      float64
+	// Teams is the number of teams every match is split into, zero or one
+	// leaves the match without teams.
+	Teams          int
+	Strategy       string
+	StrategyParams map[string]string
+	// SkillCurve widens MaxSkill for tickets that have been waiting.
+	SkillCurve SkillCurve
+	// Backfill starts matches that can not be filled with at least
+	// BackfillMinPlayers and fills the open slots of running games. Only
+	// tickets that waited BackfillMinWait start such matches.
+	Backfill           bool
+	BackfillMinPlayers int
+	BackfillMinWait    time.Duration
 }
 
-var GCalculationMode = Skill
-
 // Run is this match function's implementation of the gRPC call defined in api/matchfunction.proto.
 func (s *MatchFunctionService) Run(req *pb.RunRequest, stream pb.MatchFunction_RunServer) error {
 	// Fetch tickets for the pools specified in the Match Profile.
@@ -67,28 +77,60 @@ func (s *MatchFunctionService) Run(req *pb.RunRequest, stream pb.MatchFunction_R
 
 	matchProfile := req.GetProfile()
 
-	profileData := ProfileData{
-		ProfileName: matchProfile.Name,
-		Region:      utils.GetExtensionString(matchProfile.Extensions, utils.GMaxPlayersKey),
-		MaxPlayer:   int(utils.GetExtensionFloat64(matchProfile.Extensions, utils.GMaxPlayersKey)),
-		MaxPing:     100000,
-		MaxSkill:    int(utils.GetExtensionFloat64(matchProfile.Extensions, utils.GMaxSkillDifference)),
+	profileData, err := getProfileData(matchProfile)
+	if err != nil {
+		log.Printf("Failed to read match profile %s, got %s", matchProfile.GetName(), err.Error())
+		return err
+	}
+
+	strategy, err := NewStrategy(profileData.Strategy, profileData.StrategyParams)
+	if err != nil {
+		log.Printf("Failed to create strategy for profile %s, got %s", matchProfile.GetName(), err.Error())
+		return err
+	}
+
+	// Only tickets that may play in the region of the profile are matched.
+	poolTickets, err = gateLatency(poolTickets, profileData)
+	if err != nil {
+		log.Printf("Failed to filter tickets by latency, got %s", err.Error())
+		return err
 	}
 
+	// Running games with open slots get the first pick of the tickets.
 	proposals := []*pb.Match{}
-	if GCalculationMode == All {
-		proposals, err = makeMatches(matchProfile, poolTickets, profileData.MaxPlayer)
+	if profileData.Backfill {
+		backfills, err := matchfunction.QueryBackfillPools(stream.Context(), s.queryServiceClient, matchProfile.GetPools())
 		if err != nil {
-			log.Printf("Failed to generate matches, got %s", err.Error())
+			log.Printf("Failed to query backfills for the given pools, got %s", err.Error())
 			return err
 		}
-	} else {
-		// Generate proposals.
-		proposals, err = makeMatches2(poolTickets[utils.GPoolName], profileData)
+		proposals, poolTickets[utils.GPoolName], err = fillBackfills(backfills[utils.GPoolName], poolTickets[utils.GPoolName], profileData)
+		if err != nil {
+			log.Printf("Failed to fill backfills, got %s", err.Error())
+			return err
+		}
+	}
+
+	// Generate proposals.
+	matches, err := strategy.MakeMatches(matchProfile, profileData, poolTickets)
+	if err != nil {
+		log.Printf("Failed to generate matches, got %s", err.Error())
+		return err
+	}
+	proposals = append(proposals, matches...)
+
+	// The tickets left over start games that are filled up by backfill.
+	if profileData.Backfill && len(matchProfile.GetPools()) > 0 {
+		remaining := unmatched(poolTickets[utils.GPoolName], matches)
+		sort.Slice(remaining, func(i, j int) bool {
+			return ticket.GetSkillFromTicket(remaining[i]) < ticket.GetSkillFromTicket(remaining[j])
+		})
+		partial, err := partialMatches(remaining, matchProfile.GetPools()[0], profileData)
 		if err != nil {
-			log.Printf("Failed to generate matches, got %s", err.Error())
+			log.Printf("Failed to start matches with backfill, got %s", err.Error())
 			return err
 		}
+		proposals = append(proposals, partial...)
 	}
 
 	log.Printf("Streaming %v proposals to Open Match", len(proposals))
@@ -103,97 +145,221 @@ func (s *MatchFunctionService) Run(req *pb.RunRequest, stream pb.MatchFunction_R
 	return nil
 }
 
-func makeMatches2(tickets []*pb.Ticket, profile ProfileData) ([]*pb.Match, error) {
+// formMatches turns groups of tickets into match proposals with their quality
+// and team layout.
+func formMatches(groups [][]*pb.Ticket, profile ProfileData) ([]*pb.Match, error) {
 	matches := []*pb.Match{}
-	skillTickets := tickets
-
-	sort.Slice(skillTickets, func(i, j int) bool {
-		return ticket.GetSkillFromTicket(skillTickets[i]) < ticket.GetSkillFromTicket(skillTickets[j])
-	})
-
-	count := 0
-	for ticketIndex := 0; ticketIndex+profile.MaxPlayer-1 < len(skillTickets); ticketIndex++ {
-		mt := skillTickets[ticketIndex : ticketIndex+profile.MaxPlayer]
-		if ticket.GetSkillFromTicket(mt[len(mt)-1])-ticket.GetSkillFromTicket(mt[0]) < float64(profile.MaxSkill) {
+	for count, mt := range groups {
+		match, err := newMatch(mt, profile, count)
+		if err != nil {
+			return nil, err
+		}
 
-			avgLatency := 0.0
-			for _, t := range mt {
-				avgLatency += ticket.GetLatencyFromTicket(t, profile.Region, profile.MaxPing)
+		if profile.Teams > 1 {
+			// The strategies only form groups that fit into the teams.
+			layout, err := splitTeams(mt, profile.Teams)
+			if err != nil {
+				return nil, err
 			}
-			avgLatency /= float64(len(mt))
+			utils.SetMessage(match.Extensions, utils.MatchTeams, layout)
+		}
+		matches = append(matches, match)
+	}
 
-			qLatency := float64(0)
-			for _, t := range mt {
-				diff := ticket.GetLatencyFromTicket(t, profile.Region, profile.MaxPing) - avgLatency
-				qLatency -= diff * diff
-			}
+	// loop through and assign matches
+	return matches, nil
+}
 
-			avgSkill := 0.0
-			for _, t := range mt {
-				avgSkill += ticket.GetSkillFromTicket(t)
-			}
-			avgSkill /= float64(len(mt))
+// newMatch creates a proposal for the tickets with its quality and the
+// evaluation input derived from it.
+func newMatch(mt []*pb.Ticket, profile ProfileData, index int) (*pb.Match, error) {
+	quality, err := matchQuality(mt, profile, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	quality.Index = int32(index)
+
+	match := &pb.Match{
+		MatchId:       fmt.Sprintf("profile-%v-time-%v-%v", profile.ProfileName, time.Now().Format("2006-01-02T15:04:05.00"), index),
+		MatchProfile:  profile.ProfileName,
+		MatchFunction: matchName,
+		Tickets:       mt,
+		Extensions:    make(map[string]*anypb.Any),
+	}
+	utils.SetMessage(match.Extensions, utils.MatchQuality, quality)
+	match.Extensions[utils.GEvaluationInputKey] = utils.MustAny(&pb.DefaultEvaluationCriteria{Score: quality.Score})
+	return match, nil
+}
 
-			qSkill := 0.0
-			for _, t := range mt {
-				diff := ticket.GetSkillFromTicket(t) - avgSkill
-				qSkill -= diff * diff
+// matchQuality rates the tickets as a match, the score combines the skill,
+// latency and wait components with GScoreWeights.
+func matchQuality(mt []*pb.Ticket, profile ProfileData, now time.Time) (*simproto.MatchQuality, error) {
+	qLatency := float64(0)
+	if profile.Region != "" {
+		latencies := make([]float64, len(mt))
+		avgLatency := 0.0
+		for index, t := range mt {
+			latency, err := ticket.GetLatencyFromTicket(t, profile.Region)
+			if err != nil {
+				return nil, fmt.Errorf("ticket %s, got %w", t.GetId(), err)
 			}
+			latencies[index] = latency
+			avgLatency += latency
+		}
+		avgLatency /= float64(len(mt))
 
-			matches = append(matches, &pb.Match{
-				MatchId:       fmt.Sprintf("profile-%v-time-%v-%v", profile.ProfileName, time.Now().Format("2006-01-02T15:04:05.00"), count),
-				MatchProfile:  profile.ProfileName,
-				MatchFunction: matchName,
-				Tickets:       mt,
-				Extensions: map[string]*anypb.Any{
-					utils.GCurrentNumTickets: utils.GetAnyFromValue(float64(len(mt))),
-					utils.GCurrentNumMatches: utils.GetAnyFromValue(float64(count)),
-				},
-			})
-			count++
+		for _, latency := range latencies {
+			diff := latency - avgLatency
+			qLatency -= diff * diff
 		}
 	}
 
-	// loop through and assign matches
-	return matches, nil
+	qSkill := 0.0
+	avgSkill := averageSkill(mt)
+	for _, t := range mt {
+		diff := ticket.GetSkillFromTicket(t) - avgSkill
+		qSkill -= diff * diff
+	}
+
+	wait, err := maxWait(mt, now)
+	if err != nil {
+		return nil, err
+	}
+	quality := &simproto.MatchQuality{
+		NumTickets:     int32(len(mt)),
+		NumPlayers:     int32(countPlayers(mt)),
+		MaxWait:        durationpb.New(wait),
+		SkillQuality:   qSkill,
+		LatencyQuality: qLatency,
+	}
+	quality.Score = GScoreWeights.Score(quality)
+	return quality, nil
+}
+
+func averageSkill(mt []*pb.Ticket) float64 {
+	avgSkill := 0.0
+	for _, t := range mt {
+		avgSkill += ticket.GetSkillFromTicket(t)
+	}
+	return avgSkill / float64(len(mt))
 }
 
-func makeMatches(p *pb.MatchProfile, poolTickets map[string][]*pb.Ticket, matchPerProfile int) ([]*pb.Match, error) {
-	var matches []*pb.Match
-	count := 0
+// poolGroups takes the same number of players from every pool for each group,
+// in the order the tickets were returned.
+func poolGroups(poolTickets map[string][]*pb.Ticket, matchPerProfile int) [][]*pb.Ticket {
+	groups := [][]*pb.Ticket{}
 	for {
 		insufficientTickets := false
-		desiredRegionskillTicketsskillTickets := []*pb.Ticket{}
-		log.Printf("Match profile data Num Players Per Match %d %+v", matchPerProfile, p)
+		group := []*pb.Ticket{}
 		for pool, tickets := range poolTickets {
-			if len(tickets) < matchPerProfile {
+			taken, remaining, ok := takePlayers(tickets, matchPerProfile)
+			if !ok {
 				// This pool is completely drained out. Stop creating matches.
 				insufficientTickets = true
 				break
 			}
 
 			// Remove the Tickets from this pool and add to the match proposal.
-			desiredRegionskillTicketsskillTickets = append(desiredRegionskillTicketsskillTickets, tickets[0:matchPerProfile]...)
-			poolTickets[pool] = tickets[matchPerProfile:]
+			group = append(group, taken...)
+			poolTickets[pool] = remaining
 		}
 
 		if insufficientTickets {
 			break
 		}
+		groups = append(groups, group)
+	}
+	return groups
+}
 
-		matches = append(matches, &pb.Match{
-			MatchId:       fmt.Sprintf("profile-%v-time-%v-%v", p.GetName(), time.Now().Format("2006-01-02T15:04:05.00"), count),
-			MatchProfile:  p.GetName(),
-			MatchFunction: matchName,
-			Tickets:       desiredRegionskillTicketsskillTickets,
-			Extensions: map[string]*anypb.Any{
-				utils.GCurrentNumTickets: utils.GetAnyFromValue(float64(len(desiredRegionskillTicketsskillTickets))),
-				utils.GCurrentNumMatches: utils.GetAnyFromValue(float64(matchPerProfile)),
-			},
-		})
+// takePlayers takes tickets in order until their parties add up to exactly
+// numPlayers, skipping parties that would overflow the match. The tickets that
+// were not taken are returned in their original order.
+func takePlayers(tickets []*pb.Ticket, numPlayers int) ([]*pb.Ticket, []*pb.Ticket, bool) {
+	taken := []*pb.Ticket{}
+	remaining := []*pb.Ticket{}
+	players := 0
+	for index, t := range tickets {
+		if players == numPlayers {
+			remaining = append(remaining, tickets[index:]...)
+			break
+		}
 
-		count++
+		size := ticket.GetPartySizeFromTicket(t)
+		if players+size > numPlayers {
+			remaining = append(remaining, t)
+			continue
+		}
+		taken = append(taken, t)
+		players += size
 	}
 
-	return matches, nil
+	if players != numPlayers {
+		return nil, tickets, false
+	}
+	return taken, remaining, true
+}
+
+func countPlayers(tickets []*pb.Ticket) int {
+	players := 0
+	for _, t := range tickets {
+		players += ticket.GetPartySizeFromTicket(t)
+	}
+	return players
+}
+
+// maxWait returns the longest time any ticket in the match has been queueing.
+func maxWait(tickets []*pb.Ticket, now time.Time) (time.Duration, error) {
+	longest := time.Duration(0)
+	for _, t := range tickets {
+		wait, err := ticket.GetWaitTimeFromTicket(t, now)
+		if err != nil {
+			return 0, fmt.Errorf("ticket %s, got %w", t.GetId(), err)
+		}
+		if wait > longest {
+			longest = wait
+		}
+	}
+	return longest, nil
+}
+
+// getProfileData reads the settings of the match function from the profile
+// extensions. The player count is required, profiles without a region or
+// skill difference do not restrict them.
+func getProfileData(p *pb.MatchProfile) (ProfileData, error) {
+	settings, err := utils.GetMessage(p.GetExtensions(), utils.ProfileSettings)
+	if err != nil {
+		return ProfileData{}, err
+	}
+	if settings.GetMaxPlayers() <= 0 {
+		return ProfileData{}, fmt.Errorf("profile %s has invalid max players %d", p.GetName(), settings.GetMaxPlayers())
+	}
+
+	curve, err := skillCurveFromProto(settings.GetSkillCurve())
+	if err != nil {
+		return ProfileData{}, fmt.Errorf("profile %s, got %w", p.GetName(), err)
+	}
+	return ProfileData{
+		ProfileName:        p.GetName(),
+		Region:             settings.GetRegion(),
+		MaxPlayer:          int(settings.GetMaxPlayers()),
+		MaxPing:            settings.GetMaxPing(),
+		MaxPingSpread:      settings.GetMaxPingSpread(),
+		MaxSkill:           unlimited(settings.GetMaxSkillDifference()),
+		Teams:              int(settings.GetNumTeams()),
+		Strategy:           settings.GetStrategy(),
+		StrategyParams:     settings.GetStrategyParams(),
+		SkillCurve:         curve,
+		Backfill:           settings.GetBackfill(),
+		BackfillMinPlayers: int(settings.GetBackfillMinPlayers()),
+		BackfillMinWait:    settings.GetBackfillMinWait().AsDuration(),
+	}, nil
+}
+
+// unlimited turns the zero value of a profile limit into no limit.
+func unlimited(limit float64) float64 {
+	if limit <= 0 {
+		return math.Inf(1)
+	}
+	return limit
 }
@@ -15,18 +15,30 @@
 package mmf
 
 import (
+	"fmt"
+	"math"
+	"sort"
 	"testing"
+	"time"
 
+	utils "sim/internal"
+	"sim/internal/backfill"
+	"sim/internal/random"
 	"sim/internal/ticket"
+	simproto "sim/proto"
 
 	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/durationpb"
 	"open-match.dev/open-match/pkg/pb"
 )
 
+// rng is seeded so failures can be reproduced.
+var rng = random.New(1)
+
 func getRandomClientData(number int) []ticket.ClientMatchmakingData {
 	returnData := []ticket.ClientMatchmakingData{}
 	for i := 0; i < number; i++ {
-		clientData := ticket.CreateRandomMatchmakingData()
+		clientData := ticket.CreateRandomMatchmakingData(rng)
 		returnData = append(returnData, clientData)
 	}
 	return returnData
@@ -35,7 +47,11 @@ func getRandomClientData(number int) []ticket.ClientMatchmakingData {
 func getTicketsFromClientData(mmData []ticket.ClientMatchmakingData) []*pb.Ticket {
 	returnData := []*pb.Ticket{}
 	for index := range mmData {
-		returnData = append(returnData, ticket.MakeTicket(mmData[index]))
+		t, err := ticket.MakeTicket(mmData[index])
+		if err != nil {
+			panic(err)
+		}
+		returnData = append(returnData, t)
 	}
 	return returnData
 }
@@ -45,17 +61,36 @@ func getRandomTicketDataFromNum(number int) []*pb.Ticket {
 	return getTicketsFromClientData(clientData)
 }
 
-func TestBasicSkill(t *testing.T) {
-	require := require.New(t)
-	numPlayersPerMatch := 10
+// skillMatches runs the skill_window strategy with the partition mode on the
+// tickets.
+func skillMatches(mode PartitionMode, tickets []*pb.Ticket, profile ProfileData) ([]*pb.Match, error) {
+	p := &pb.MatchProfile{Name: profile.ProfileName}
+	return skillWindowStrategy{mode: mode}.MakeMatches(p, profile, map[string][]*pb.Ticket{utils.GPoolName: tickets})
+}
 
-	profileData := ProfileData{
+// testProfile returns a europe profile that does not limit the ping.
+func testProfile(maxPlayer int, maxSkill float64) ProfileData {
+	return ProfileData{
 		ProfileName: "test_profile",
 		Region:      "europe",
-		MaxPlayer:   10,
-		MaxPing:     100000,
-		MaxSkill:    50,
+		MaxPlayer:   maxPlayer,
+		MaxSkill:    maxSkill,
+	}
+}
+
+// withIDs names the tickets ticket-0, ticket-1 and so on in their order.
+func withIDs(tickets []*pb.Ticket) []*pb.Ticket {
+	for index, t := range tickets {
+		t.Id = fmt.Sprintf("ticket-%d", index)
 	}
+	return tickets
+}
+
+func TestBasicSkill(t *testing.T) {
+	require := require.New(t)
+	numPlayersPerMatch := 10
+
+	profileData := testProfile(10, 50)
 
 	{
 		clientData := getRandomClientData(numPlayersPerMatch)
@@ -66,13 +101,13 @@ func TestBasicSkill(t *testing.T) {
 			}
 		}
 		tickets := getTicketsFromClientData(clientData)
-		matches, _ := makeMatches2(tickets, profileData)
+		matches, _ := skillMatches(PartitionGreedy, tickets, profileData)
 		require.True(len(matches) > 0, "Created match")
 	}
 
 	{
 		tickets := getRandomTicketDataFromNum(numPlayersPerMatch / 2)
-		matches, _ := makeMatches2(tickets, profileData)
+		matches, _ := skillMatches(PartitionGreedy, tickets, profileData)
 		require.True(len(matches) == 0, "Did not create match with too few people")
 	}
 
@@ -85,8 +120,654 @@ func TestBasicSkill(t *testing.T) {
 			}
 		}
 		tickets := getTicketsFromClientData(clientData)
-		matches, _ := makeMatches2(tickets, profileData)
+		matches, _ := skillMatches(PartitionGreedy, tickets, profileData)
 		require.True(len(matches) > 0, "Created match")
 
 	}
 }
+
+func TestPartySize(t *testing.T) {
+	require := require.New(t)
+
+	profileData := testProfile(16, 50)
+
+	{
+		// Four squads of four fill a 16 player lobby.
+		clientData := []ticket.ClientMatchmakingData{}
+		for i := 0; i < 4; i++ {
+			party, err := ticket.CreateRandomParty(rng, 4, ticket.AggregateMax)
+			require.NoError(err)
+			party.Skill = 10
+			party.RegionData.Pings = map[string]float64{"europe": 0.0}
+			clientData = append(clientData, party)
+		}
+		tickets := getTicketsFromClientData(clientData)
+		matches, _ := skillMatches(PartitionGreedy, tickets, profileData)
+		require.Len(matches, 1, "Created match from parties")
+		require.Len(matches[0].Tickets, 4)
+	}
+
+	{
+		// Three squads of five can not fill the lobby exactly.
+		clientData := []ticket.ClientMatchmakingData{}
+		for i := 0; i < 3; i++ {
+			party, err := ticket.CreateRandomParty(rng, 5, ticket.AggregateMax)
+			require.NoError(err)
+			party.Skill = 10
+			party.RegionData.Pings = map[string]float64{"europe": 0.0}
+			clientData = append(clientData, party)
+		}
+		tickets := getTicketsFromClientData(clientData)
+		matches, _ := skillMatches(PartitionGreedy, tickets, profileData)
+		require.Len(matches, 0, "Did not create match with too few players")
+	}
+}
+
+func TestDisjointMatches(t *testing.T) {
+	require := require.New(t)
+
+	profileData := testProfile(4, 50)
+
+	for _, mode := range []PartitionMode{PartitionGreedy, PartitionMinSpread} {
+		clientData := getRandomClientData(200)
+		for index := 0; index < 30; index++ {
+			party, err := ticket.CreateRandomParty(rng, 2+index%3, ticket.AggregateMax)
+			require.NoError(err)
+			clientData = append(clientData, party)
+		}
+		for index := range clientData {
+			clientData[index].Skill = float64(rng.Intn(500))
+			clientData[index].RegionData.Pings = map[string]float64{"europe": 0.0}
+		}
+		tickets := withIDs(getTicketsFromClientData(clientData))
+
+		matches, err := skillMatches(mode, tickets, profileData)
+		require.NoError(err)
+		require.NotEmpty(matches, "Created matches in %s mode", mode)
+
+		seen := map[string]bool{}
+		for _, match := range matches {
+			require.Equal(profileData.MaxPlayer, countPlayers(match.Tickets))
+			require.Less(skillSpread(match.Tickets), profileData.MaxSkill)
+			for _, tt := range match.Tickets {
+				require.False(seen[tt.Id], "Ticket %s is in more than one match in %s mode", tt.Id, mode)
+				seen[tt.Id] = true
+			}
+		}
+	}
+}
+
+func TestMinSpreadPartition(t *testing.T) {
+	require := require.New(t)
+
+	profileData := testProfile(2, 10)
+
+	clientData := getRandomClientData(3)
+	for index, skill := range []float64{0, 9, 10} {
+		clientData[index].Skill = skill
+		clientData[index].RegionData.Pings = map[string]float64{"europe": 0.0}
+	}
+
+	// Greedy pairs the first two tickets, min-spread pairs the two closest ones.
+	greedy, err := partition(getTicketsFromClientData(clientData), profileData, PartitionGreedy)
+	require.NoError(err)
+	require.Len(greedy, 1)
+	require.Equal(9.0, skillSpread(greedy[0]))
+
+	minSpread, err := partition(getTicketsFromClientData(clientData), profileData, PartitionMinSpread)
+	require.NoError(err)
+	require.Len(minSpread, 1)
+	require.Equal(1.0, skillSpread(minSpread[0]))
+}
+
+// skillTickets creates single player tickets with the skills and the same
+// ping, so only the skill tells matches apart.
+func skillTickets(skills ...float64) []*pb.Ticket {
+	clientData := getRandomClientData(len(skills))
+	for index, skill := range skills {
+		clientData[index].Skill = skill
+		clientData[index].RegionData.Pings = map[string]float64{"europe": 0.0}
+	}
+	return withIDs(getTicketsFromClientData(clientData))
+}
+
+// partitionScore rates the matches the way the min-spread partition does.
+func partitionScore(t *testing.T, groups [][]*pb.Ticket, profile ProfileData, now time.Time) score {
+	total := score{}
+	for _, mt := range groups {
+		quality, err := matchQuality(mt, profile, now)
+		require.NoError(t, err)
+		total = total.add(score{matches: 1, total: quality.GetScore()})
+	}
+	return total
+}
+
+// bruteForcePartition tries every way of forming disjoint matches from the
+// single player tickets and returns the best score.
+func bruteForcePartition(t *testing.T, tickets []*pb.Ticket, profile ProfileData, now time.Time) score {
+	if len(tickets) == 0 {
+		return score{}
+	}
+
+	// The first ticket is either left out or matched with any combination of
+	// the others.
+	first, rest := tickets[0], tickets[1:]
+	best := bruteForcePartition(t, rest, profile, now)
+	for mask := 0; mask < 1<<len(rest); mask++ {
+		mt, left := []*pb.Ticket{first}, []*pb.Ticket{}
+		for index, other := range rest {
+			if mask&(1<<index) != 0 {
+				mt = append(mt, other)
+			} else {
+				left = append(left, other)
+			}
+		}
+		if len(mt) != profile.MaxPlayer {
+			continue
+		}
+		valid, err := validMatch(mt, profile)
+		require.NoError(t, err)
+		if !valid {
+			continue
+		}
+
+		candidate := partitionScore(t, [][]*pb.Ticket{mt}, profile, now).add(bruteForcePartition(t, left, profile, now))
+		if candidate.better(best) {
+			best = candidate
+		}
+	}
+	return best
+}
+
+func TestMinSpreadIsOptimal(t *testing.T) {
+	require := require.New(t)
+
+	profileData := testProfile(3, 50)
+	now := time.Now()
+
+	// Greedy takes the three lowest tickets, which leaves 0 and 40 in one match.
+	// Shifting both matches up by one ticket scores better.
+	tickets := skillTickets(0, 40, 45, 50, 52, 60, 90)
+	optimal := bruteForcePartition(t, tickets, profileData, now)
+	require.Equal(2, optimal.matches)
+
+	greedy, err := partition(tickets, profileData, PartitionGreedy)
+	require.NoError(err)
+	require.True(optimal.better(partitionScore(t, greedy, profileData, now)), "Greedy is not optimal")
+
+	minSpread, err := partition(tickets, profileData, PartitionMinSpread)
+	require.NoError(err)
+	got := partitionScore(t, minSpread, profileData, now)
+	require.Equal(optimal.matches, got.matches)
+	require.InDelta(optimal.total, got.total, 1e-9)
+	require.Equal([]*pb.Ticket{tickets[1], tickets[2], tickets[3]}, minSpread[0])
+
+	// Single player queues are always split optimally.
+	for run := 0; run < 20; run++ {
+		skills := []float64{}
+		for index := 0; index < 9; index++ {
+			skills = append(skills, float64(rng.Intn(150)))
+		}
+		sort.Float64s(skills)
+		tickets := skillTickets(skills...)
+
+		optimal := bruteForcePartition(t, tickets, profileData, now)
+		minSpread, err := partition(tickets, profileData, PartitionMinSpread)
+		require.NoError(err)
+		got := partitionScore(t, minSpread, profileData, now)
+		require.Equal(optimal.matches, got.matches, "Skills %v", skills)
+		require.InDelta(optimal.total, got.total, 1e-6, "Skills %v", skills)
+	}
+}
+
+func TestMatchScore(t *testing.T) {
+	require := require.New(t)
+
+	weights := GScoreWeights
+	GScoreWeights = ScoreWeights{Skill: 2, Latency: 0.5}
+	defer func() { GScoreWeights = weights }()
+
+	profileData := testProfile(2, 50)
+
+	clientData := getRandomClientData(2)
+	for index := range clientData {
+		clientData[index].Skill = float64(index) * 10
+		clientData[index].RegionData.Pings = map[string]float64{"europe": float64(index) * 20}
+	}
+	matches, err := skillMatches(PartitionGreedy, getTicketsFromClientData(clientData), profileData)
+	require.NoError(err)
+	require.Len(matches, 1)
+
+	quality, err := utils.GetMessage(matches[0].Extensions, utils.MatchQuality)
+	require.NoError(err)
+	require.Equal(-50.0, quality.SkillQuality)
+	require.Equal(-200.0, quality.LatencyQuality)
+
+	// 2 * -50 + 0.5 * -200
+	require.Equal(-200.0, quality.Score)
+
+	criteria := &pb.DefaultEvaluationCriteria{}
+	require.NoError(matches[0].Extensions[utils.GEvaluationInputKey].UnmarshalTo(criteria))
+	require.Equal(-200.0, criteria.Score)
+
+	// 2 * -50 + 0.5 * -200 + 3 * 10
+	withWait := ScoreWeights{Skill: 2, Latency: 0.5, Wait: 3}
+	require.Equal(-170.0, withWait.Score(&simproto.MatchQuality{
+		SkillQuality:   -50,
+		LatencyQuality: -200,
+		MaxWait:        durationpb.New(10 * time.Second),
+	}))
+}
+
+func TestParseScoreWeights(t *testing.T) {
+	require := require.New(t)
+
+	weights, err := ParseScoreWeights("")
+	require.NoError(err)
+	require.Equal(GScoreWeights, weights)
+
+	weights, err = ParseScoreWeights("latency=0.5, wait=10")
+	require.NoError(err)
+	require.Equal(ScoreWeights{Skill: 1, Latency: 0.5, Wait: 10}, weights, "Unlisted components keep their default")
+
+	for _, spec := range []string{"skill", "foo=1", "wait=x"} {
+		_, err := ParseScoreWeights(spec)
+		require.Error(err, "Rejected %q", spec)
+	}
+}
+
+func TestTeamSplit(t *testing.T) {
+	require := require.New(t)
+
+	{
+		// Sixteen single players with skills 1 to 16 can be split evenly.
+		clientData := getRandomClientData(16)
+		for index := range clientData {
+			clientData[index].Skill = float64(index + 1)
+		}
+		tickets := withIDs(getTicketsFromClientData(clientData))
+
+		layout, err := splitTeams(tickets, 4)
+		require.NoError(err)
+		require.Len(layout.Teams, 4)
+		for _, team := range layout.Teams {
+			require.Equal(int32(4), team.Players)
+		}
+		require.LessOrEqual(skillGap(layout), 2.0)
+	}
+
+	{
+		// Parties stay on one team.
+		clientData := []ticket.ClientMatchmakingData{}
+		for _, size := range []int{4, 3, 2, 2, 1, 1, 1, 1, 1} {
+			if size == 1 {
+				clientData = append(clientData, ticket.CreateRandomMatchmakingData(rng))
+				continue
+			}
+			party, err := ticket.CreateRandomParty(rng, size, ticket.AggregateMax)
+			require.NoError(err)
+			clientData = append(clientData, party)
+		}
+		tickets := withIDs(getTicketsFromClientData(clientData))
+
+		layout, err := splitTeams(tickets, 4)
+		require.NoError(err)
+
+		seen := map[string]bool{}
+		for _, team := range layout.Teams {
+			require.Equal(int32(4), team.Players)
+			for _, id := range team.TicketIds {
+				require.False(seen[id], "Ticket %s is on more than one team", id)
+				seen[id] = true
+			}
+		}
+		require.Len(seen, len(tickets))
+	}
+
+	{
+		// Four trios and a squad do not fit into teams of four.
+		clientData := []ticket.ClientMatchmakingData{}
+		for _, size := range []int{4, 3, 3, 3, 3} {
+			party, err := ticket.CreateRandomParty(rng, size, ticket.AggregateMax)
+			require.NoError(err)
+			clientData = append(clientData, party)
+		}
+		_, err := splitTeams(getTicketsFromClientData(clientData), 4)
+		require.Error(err)
+	}
+}
+
+func TestTeamWindows(t *testing.T) {
+	require := require.New(t)
+
+	profileData := testProfile(16, 50)
+	profileData.Teams = 4
+
+	// The lowest window takes a squad and four trios, which do not fit into
+	// teams of four. The trios fill up with the single players instead.
+	clientData := []ticket.ClientMatchmakingData{}
+	for index, size := range []int{4, 3, 3, 3, 3, 1, 1, 1, 1} {
+		party := ticket.CreateRandomMatchmakingData(rng)
+		if size > 1 {
+			var err error
+			party, err = ticket.CreateRandomParty(rng, size, ticket.AggregateMax)
+			require.NoError(err)
+		}
+		party.Skill = float64(index)
+		party.RegionData.Pings = map[string]float64{"europe": 0.0}
+		clientData = append(clientData, party)
+	}
+
+	for _, mode := range []PartitionMode{PartitionGreedy, PartitionMinSpread} {
+		tickets := withIDs(getTicketsFromClientData(clientData))
+		groups, err := partition(tickets, profileData, mode)
+		require.NoError(err)
+		require.Len(groups, 1, "Created a match in %s mode", mode)
+		require.NotContains(groups[0], tickets[0], "Left out the squad in %s mode", mode)
+
+		matches, err := formMatches(groups, profileData)
+		require.NoError(err)
+		require.Len(matches, 1)
+		require.True(utils.Has(matches[0].Extensions, utils.MatchTeams))
+	}
+}
+
+// skillSpread returns the difference between the highest and lowest skill.
+func skillSpread(mt []*pb.Ticket) float64 {
+	lowest, highest := math.Inf(1), math.Inf(-1)
+	for _, t := range mt {
+		lowest = math.Min(lowest, ticket.GetSkillFromTicket(t))
+		highest = math.Max(highest, ticket.GetSkillFromTicket(t))
+	}
+	return highest - lowest
+}
+
+// skillGap returns the difference between the strongest and weakest team.
+func skillGap(layout *simproto.TeamLayout) float64 {
+	lowest, highest := math.Inf(1), math.Inf(-1)
+	for _, team := range layout.GetTeams() {
+		lowest = math.Min(lowest, team.GetSkill())
+		highest = math.Max(highest, team.GetSkill())
+	}
+	return highest - lowest
+}
+
+func TestStrategies(t *testing.T) {
+	require := require.New(t)
+
+	profileData := testProfile(4, 50)
+	profile := &pb.MatchProfile{Name: profileData.ProfileName}
+
+	for _, name := range StrategyNames() {
+		strategy, err := NewStrategy(name, nil)
+		require.NoError(err)
+
+		clientData := getRandomClientData(40)
+		for index := range clientData {
+			clientData[index].Skill = float64(rng.Intn(100))
+			clientData[index].RegionData.Pings = map[string]float64{"europe": float64(rng.Intn(100))}
+		}
+		tickets := withIDs(getTicketsFromClientData(clientData))
+
+		matches, err := strategy.MakeMatches(profile, profileData, map[string][]*pb.Ticket{utils.GPoolName: tickets})
+		require.NoError(err)
+		require.NotEmpty(matches, "Strategy %s created matches", name)
+
+		seen := map[string]bool{}
+		for _, match := range matches {
+			require.Equal(profileData.MaxPlayer, countPlayers(match.Tickets))
+			require.True(utils.Has(match.Extensions, utils.MatchQuality), "Strategy %s sets the match quality", name)
+			require.Contains(match.Extensions, utils.GEvaluationInputKey, "Strategy %s sets the evaluation input", name)
+			for _, tt := range match.Tickets {
+				require.False(seen[tt.Id], "Ticket %s is in more than one match of strategy %s", tt.Id, name)
+				seen[tt.Id] = true
+			}
+		}
+	}
+
+	{
+		// The freshest ticket has the lowest ping and the oldest one the
+		// highest, one of them is left out of the match.
+		clientData := getRandomClientData(5)
+		for index := range clientData {
+			clientData[index].Skill = 10
+			clientData[index].RegionData.Pings = map[string]float64{"europe": float64(10 + index*20)}
+			clientData[index].OriginalCreateTime = time.Now().Add(-time.Duration(index) * time.Minute)
+		}
+		matchedIDs := func(name string) []string {
+			strategy, err := NewStrategy(name, nil)
+			require.NoError(err)
+			tickets := withIDs(getTicketsFromClientData(clientData))
+			matches, err := strategy.MakeMatches(profile, profileData, map[string][]*pb.Ticket{utils.GPoolName: tickets})
+			require.NoError(err)
+			require.Len(matches, 1)
+			ids := []string{}
+			for _, tt := range matches[0].Tickets {
+				ids = append(ids, tt.Id)
+			}
+			return ids
+		}
+
+		require.ElementsMatch([]string{"ticket-1", "ticket-2", "ticket-3", "ticket-4"}, matchedIDs("fifo"), "fifo takes the oldest tickets first")
+		require.ElementsMatch([]string{"ticket-0", "ticket-1", "ticket-2", "ticket-3"}, matchedIDs("latency_first"), "latency_first takes the lowest pings first")
+	}
+
+	{
+		// Every pool contributes the same number of players, and the match gets
+		// its quality and teams like the other strategies.
+		teamProfile := testProfile(2, 50)
+		teamProfile.Teams = 2
+		poolTickets := map[string][]*pb.Ticket{}
+		for index, pool := range []string{"first", "second"} {
+			clientData := getRandomClientData(3)
+			for i := range clientData {
+				clientData[i].RegionData.Pings = map[string]float64{"europe": 0.0}
+			}
+			tickets := withIDs(getTicketsFromClientData(clientData))
+			for _, tt := range tickets {
+				tt.Id = fmt.Sprintf("%s-%d", tt.Id, index)
+			}
+			poolTickets[pool] = tickets
+		}
+
+		matches, err := poolsStrategy{}.MakeMatches(profile, teamProfile, poolTickets)
+		require.NoError(err)
+		require.Len(matches, 1)
+		require.Len(matches[0].Tickets, 4)
+		require.True(utils.Has(matches[0].Extensions, utils.MatchTeams))
+	}
+
+	require.Equal(utils.StrategyNames(), StrategyNames(), "Every declared strategy is registered")
+	_, err := NewStrategy("unknown", nil)
+	require.Error(err)
+	_, err = NewStrategy("skill_window", map[string]string{"partition": "random"})
+	require.Error(err)
+	_, err = NewStrategy("fifo", map[string]string{"partition": "greedy"})
+	require.Error(err, "Unknown parameters are rejected")
+}
+
+func TestSkillCurve(t *testing.T) {
+	require := require.New(t)
+
+	profileData := testProfile(2, 10)
+	profileData.SkillCurve = SkillCurve{{Wait: time.Minute, MaxSkill: 110}}
+	require.Equal(60.0, profileData.SkillCurve.At(profileData.MaxSkill, 30*time.Second))
+	require.Equal(110.0, profileData.SkillCurve.At(profileData.MaxSkill, time.Hour))
+
+	makeTickets := func(waits ...time.Duration) []*pb.Ticket {
+		clientData := getRandomClientData(len(waits))
+		for index, wait := range waits {
+			clientData[index].Skill = float64(index) * 50
+			clientData[index].RegionData.Pings = map[string]float64{"europe": 0.0}
+			clientData[index].OriginalCreateTime = time.Now().Add(-wait)
+		}
+		return getTicketsFromClientData(clientData)
+	}
+
+	matches, err := skillMatches(PartitionGreedy, makeTickets(0, 0), profileData)
+	require.NoError(err)
+	require.Len(matches, 0, "Fresh tickets only accept close skills")
+
+	matches, err = skillMatches(PartitionGreedy, makeTickets(2*time.Minute, 0), profileData)
+	require.NoError(err)
+	require.Len(matches, 0, "The fresh ticket does not accept the waiting one")
+
+	matches, err = skillMatches(PartitionGreedy, makeTickets(2*time.Minute, 90*time.Second), profileData)
+	require.NoError(err)
+	require.Len(matches, 1, "Both waiting tickets accept each other")
+}
+
+func TestLatencyGating(t *testing.T) {
+	require := require.New(t)
+
+	profileData := testProfile(2, 50)
+	profileData.MaxPing = 100
+	profileData.MaxPingSpread = 50
+
+	makeTicket := func(id string, pings map[string]float64) *pb.Ticket {
+		clientData := getRandomClientData(1)[0]
+		clientData.Skill = 10
+		clientData.RegionData.Pings = pings
+		tt := getTicketsFromClientData([]ticket.ClientMatchmakingData{clientData})[0]
+		tt.Id = id
+		return tt
+	}
+
+	tickets := []*pb.Ticket{
+		// Queues for both regions and is close to europe.
+		makeTicket("close", map[string]float64{"europe": 40, "us": 120}),
+		// Queues for both regions but is too far from europe.
+		makeTicket("far", map[string]float64{"europe": 180, "us": 30}),
+		// Europe is the best region of a player that is far from everything, it
+		// is let in despite the max ping.
+		makeTicket("remote", map[string]float64{"europe": 250, "us": 280}),
+		// Never measured europe.
+		makeTicket("us_only", map[string]float64{"us": 20}),
+	}
+
+	gated, err := gateLatency(map[string][]*pb.Ticket{utils.GPoolName: tickets}, profileData)
+	require.NoError(err)
+	ids := []string{}
+	for _, tt := range gated[utils.GPoolName] {
+		ids = append(ids, tt.Id)
+	}
+	require.ElementsMatch([]string{"close", "remote"}, ids)
+
+	// The spread uses the real ping of the remote ticket, 210ms more than the
+	// close one, not the max ping it was let in with.
+	profileData.MaxPingSpread = 60
+	valid, err := withinPingSpread(gated[utils.GPoolName], profileData)
+	require.NoError(err)
+	require.False(valid, "Ping spread is above the limit")
+
+	profileData.MaxPingSpread = 210
+	valid, err = withinPingSpread(gated[utils.GPoolName], profileData)
+	require.NoError(err)
+	require.True(valid, "Ping spread is within the limit")
+
+	// The latency quality uses the real pings as well, 2 * 105^2.
+	match, err := newMatch(gated[utils.GPoolName], profileData, 0)
+	require.NoError(err)
+	quality, err := utils.GetMessage(match.Extensions, utils.MatchQuality)
+	require.NoError(err)
+	require.Equal(-22050.0, quality.LatencyQuality)
+
+	// The us profile takes the tickets close to the us instead.
+	profileData.Region = "us"
+	gated, err = gateLatency(map[string][]*pb.Ticket{utils.GPoolName: tickets}, profileData)
+	require.NoError(err)
+	ids = []string{}
+	for _, tt := range gated[utils.GPoolName] {
+		ids = append(ids, tt.Id)
+	}
+	require.ElementsMatch([]string{"far", "us_only"}, ids)
+}
+
+func TestBackfill(t *testing.T) {
+	require := require.New(t)
+
+	profileData := testProfile(4, 50)
+	profileData.Backfill = true
+	pool := &pb.Pool{
+		Name:              utils.GPoolName,
+		TagPresentFilters: []*pb.TagPresentFilter{{Tag: "europe"}},
+		DoubleRangeFilters: []*pb.DoubleRangeFilter{
+			{DoubleArg: utils.GSkillArg, Min: 0, Max: 100},
+			{DoubleArg: "latency", Min: 0, Max: 200},
+		},
+	}
+
+	makeTickets := func(skills ...float64) []*pb.Ticket {
+		clientData := getRandomClientData(len(skills))
+		for index, skill := range skills {
+			clientData[index].Skill = skill
+			clientData[index].RegionData.Pings = map[string]float64{"europe": 0.0}
+		}
+		tickets := withIDs(getTicketsFromClientData(clientData))
+		return tickets
+	}
+
+	{
+		// A running game with two open slots takes the ticket close to its skill.
+		running := backfill.New(pool, &simproto.BackfillState{MaxPlayers: 4, OpenSlots: 2, Skill: 10})
+		running.Id = "running"
+		require.Equal(map[string]float64{utils.GSkillArg: 10}, running.SearchFields.DoubleArgs, "Only the skill filter is set")
+		matches, remaining, err := fillBackfills([]*pb.Backfill{running}, makeTickets(12, 500), profileData)
+		require.NoError(err)
+		require.Len(matches, 1)
+		require.Len(matches[0].Tickets, 1)
+		require.Equal("ticket-0", matches[0].Tickets[0].Id)
+		require.False(matches[0].AllocateGameserver)
+		require.Len(remaining, 1)
+
+		state, err := backfill.GetState(matches[0].Backfill)
+		require.NoError(err)
+		require.Equal(int32(1), state.OpenSlots)
+		require.Equal("running", matches[0].Backfill.Id)
+	}
+
+	{
+		// Three players can not fill a match of four, they start a game with
+		// one open slot.
+		matches, err := partialMatches(makeTickets(10, 20, 30), pool, profileData)
+		require.NoError(err)
+		require.Len(matches, 1)
+		require.True(matches[0].AllocateGameserver)
+		require.Len(matches[0].Tickets, 3)
+
+		state, err := backfill.GetState(matches[0].Backfill)
+		require.NoError(err)
+		require.Equal(int32(1), state.OpenSlots)
+		require.Equal(20.0, state.Skill)
+		require.Equal(20.0, matches[0].Backfill.SearchFields.DoubleArgs[utils.GSkillArg])
+		require.NotContains(matches[0].Backfill.SearchFields.DoubleArgs, "latency")
+		require.Contains(matches[0].Backfill.SearchFields.Tags, "europe")
+	}
+
+	{
+		// A single player is below the minimum to start a game.
+		matches, err := partialMatches(makeTickets(10), pool, profileData)
+		require.NoError(err)
+		require.Len(matches, 0)
+	}
+
+	{
+		// Only the tickets that waited long enough start a game, the fresh one
+		// keeps waiting for a full match.
+		waitProfile := profileData
+		waitProfile.BackfillMinWait = time.Minute
+		clientData := getRandomClientData(3)
+		for index, wait := range []time.Duration{0, 2 * time.Minute, 3 * time.Minute} {
+			clientData[index].Skill = 10
+			clientData[index].RegionData.Pings = map[string]float64{"europe": 0.0}
+			clientData[index].OriginalCreateTime = time.Now().Add(-wait)
+		}
+		tickets := withIDs(getTicketsFromClientData(clientData))
+
+		matches, err := partialMatches(tickets, pool, waitProfile)
+		require.NoError(err)
+		require.Len(matches, 1)
+		require.Len(matches[0].Tickets, 2)
+		require.NotContains(matches[0].Tickets, tickets[0])
+	}
+}
@@ -0,0 +1,191 @@
+package mmf
+
+import (
+	"time"
+
+	utils "sim/internal"
+	"sim/internal/ticket"
+
+	"open-match.dev/open-match/pkg/pb"
+)
+
+// PartitionMode selects how the skill_window strategy splits the skill sorted
+// tickets into matches. Both modes produce disjoint matches.
+type PartitionMode string
+
+const (
+	// PartitionGreedy takes the first window of tickets that forms a valid match,
+	// removes it and keeps going.
+	PartitionGreedy PartitionMode = utils.PartitionGreedy
+	// PartitionMinSpread picks the windows of consecutive tickets that form the
+	// most matches, ties are broken by the highest summed match score, which
+	// favours a small skill spread within the matches. Matches do not interleave
+	// tickets, a party that overshoots a window is left out of it.
+	PartitionMinSpread PartitionMode = utils.PartitionMinSpread
+)
+
+var GPartitionMode = PartitionGreedy
+
+func ParsePartitionMode(name string) (PartitionMode, error) {
+	if err := utils.CheckPartitionMode(name); err != nil {
+		return "", err
+	}
+	return PartitionMode(name), nil
+}
+
+// partition splits the skill sorted tickets into disjoint matches.
+func partition(sorted []*pb.Ticket, profile ProfileData, mode PartitionMode) ([][]*pb.Ticket, error) {
+	if mode == PartitionMinSpread {
+		return partitionMinSpread(sorted, profile)
+	}
+	return partitionGreedy(sorted, profile)
+}
+
+func partitionGreedy(sorted []*pb.Ticket, profile ProfileData) ([][]*pb.Ticket, error) {
+	groups := [][]*pb.Ticket{}
+	remaining := sorted
+	for index := 0; index < len(remaining); {
+		mt, rest, ok := takePlayers(remaining[index:], profile.MaxPlayer)
+		if !ok {
+			// A later window can still fill up when a party at the start of this
+			// one made the player count overshoot.
+			index++
+			continue
+		}
+		valid, err := validMatch(mt, profile)
+		if err != nil {
+			return nil, err
+		}
+		if !valid {
+			index++
+			continue
+		}
+
+		groups = append(groups, mt)
+		// Tickets skipped by takePlayers stay available for the next windows.
+		remaining = append(remaining[:index:index], rest...)
+	}
+	return groups, nil
+}
+
+// score orders partitions, forming more matches is always better and a higher
+// summed match score breaks ties.
+type score struct {
+	matches int
+	total   float64
+}
+
+func (s score) add(o score) score {
+	return score{matches: s.matches + o.matches, total: s.total + o.total}
+}
+
+func (s score) better(o score) bool {
+	if s.matches != o.matches {
+		return s.matches > o.matches
+	}
+	return s.total > o.total
+}
+
+// partitionMinSpread runs a dynamic program over the sorted tickets. The only
+// match that can start at a ticket is the window takePlayers forms from it, and
+// a match ends the range of tickets it spans, so best[i] is the best partition
+// of the tickets from i on: either the ticket at i is left out, or its window
+// is taken and the partition continues after it.
+func partitionMinSpread(sorted []*pb.Ticket, profile ProfileData) ([][]*pb.Ticket, error) {
+	now := time.Now()
+	n := len(sorted)
+	best := make([]score, n+1)
+	// next[i] is the index the partition continues at when the match starting at
+	// i is taken, zero if it is skipped.
+	next := make([]int, n+1)
+	windows := make([][]*pb.Ticket, n)
+
+	for start := n - 1; start >= 0; start-- {
+		best[start] = best[start+1]
+
+		mt, end, ok := window(sorted, start, profile.MaxPlayer)
+		if !ok {
+			continue
+		}
+		valid, err := validMatch(mt, profile)
+		if err != nil {
+			return nil, err
+		}
+		if !valid {
+			continue
+		}
+		quality, err := matchQuality(mt, profile, now)
+		if err != nil {
+			return nil, err
+		}
+
+		candidate := score{matches: 1, total: quality.GetScore()}.add(best[end])
+		if candidate.better(best[start]) {
+			best[start] = candidate
+			next[start] = end
+			windows[start] = mt
+		}
+	}
+
+	groups := [][]*pb.Ticket{}
+	for index := 0; index < n; {
+		if next[index] == 0 {
+			index++
+			continue
+		}
+		groups = append(groups, windows[index])
+		index = next[index]
+	}
+	return groups, nil
+}
+
+// window forms the match takePlayers would take from the ticket at start and
+// returns the index after its last ticket.
+func window(sorted []*pb.Ticket, start int, numPlayers int) ([]*pb.Ticket, int, bool) {
+	if ticket.GetPartySizeFromTicket(sorted[start]) > numPlayers {
+		return nil, 0, false
+	}
+	mt, _, ok := takePlayers(sorted[start:], numPlayers)
+	if !ok {
+		return nil, 0, false
+	}
+
+	end := start
+	for taken := 0; taken < len(mt); end++ {
+		if sorted[end] == mt[taken] {
+			taken++
+		}
+	}
+	return mt, end, true
+}
+
+// validMatch returns whether the tickets are compatible and their parties can
+// be split into the teams of the profile, so windows that can not be split are
+// skipped instead of taking tickets away from later windows.
+func validMatch(mt []*pb.Ticket, profile ProfileData) (bool, error) {
+	valid, err := compatible(mt, profile)
+	if err != nil || !valid {
+		return false, err
+	}
+	return fitsTeams(mt, profile), nil
+}
+
+// fitsTeams returns whether the parties can be split into the teams of the
+// profile.
+func fitsTeams(mt []*pb.Ticket, profile ProfileData) bool {
+	if profile.Teams <= 1 {
+		return true
+	}
+	_, err := splitTeams(mt, profile.Teams)
+	return err == nil
+}
+
+// compatible returns whether every ticket accepts the skill of the others and
+// the pings of the match are close enough.
+func compatible(mt []*pb.Ticket, profile ProfileData) (bool, error) {
+	valid, err := acceptsAll(mt, profile, time.Now())
+	if err != nil || !valid {
+		return false, err
+	}
+	return withinPingSpread(mt, profile)
+}
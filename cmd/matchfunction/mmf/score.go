@@ -0,0 +1,60 @@
+package mmf
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	simproto "sim/proto"
+)
+
+// ScoreWeights combines the quality components of a match into the score the
+// evaluator uses to pick between overlapping proposals. Skill and latency
+// qualities are negative, the wait rewards matches with long waiting tickets.
+type ScoreWeights struct {
+	Skill   float64
+	Latency float64
+	// Wait is the weight per second of the longest wait in the match.
+	Wait float64
+}
+
+var GScoreWeights = ScoreWeights{Skill: 1, Latency: 1}
+
+func (w ScoreWeights) Score(quality *simproto.MatchQuality) float64 {
+	return w.Skill*quality.GetSkillQuality() +
+		w.Latency*quality.GetLatencyQuality() +
+		w.Wait*quality.GetMaxWait().AsDuration().Seconds()
+}
+
+// ParseScoreWeights parses comma separated component=weight pairs, for example
+// "skill=1,latency=0.5,wait=10". Components that are not listed keep their
+// default weight.
+func ParseScoreWeights(spec string) (ScoreWeights, error) {
+	weights := GScoreWeights
+	if strings.TrimSpace(spec) == "" {
+		return weights, nil
+	}
+
+	for _, entry := range strings.Split(spec, ",") {
+		name, value, ok := strings.Cut(strings.TrimSpace(entry), "=")
+		if !ok {
+			return weights, fmt.Errorf("expected component=weight, got %q", entry)
+		}
+		weight, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return weights, fmt.Errorf("invalid weight in %q, got %w", entry, err)
+		}
+
+		switch name {
+		case "skill":
+			weights.Skill = weight
+		case "latency":
+			weights.Latency = weight
+		case "wait":
+			weights.Wait = weight
+		default:
+			return weights, fmt.Errorf("unknown score component %q, expected skill, latency or wait", name)
+		}
+	}
+	return weights, nil
+}
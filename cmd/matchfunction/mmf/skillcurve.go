@@ -0,0 +1,83 @@
+package mmf
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"sim/internal/ticket"
+	simproto "sim/proto"
+
+	"open-match.dev/open-match/pkg/pb"
+)
+
+// SkillCurvePoint is the skill difference a ticket accepts after waiting for
+// Wait.
+type SkillCurvePoint struct {
+	Wait     time.Duration
+	MaxSkill float64
+}
+
+// SkillCurve widens the skill window of a ticket with its wait time. Between
+// points the window grows linearly, starting at the window of the profile.
+type SkillCurve []SkillCurvePoint
+
+func skillCurveFromProto(points []*simproto.SkillWindowPoint) (SkillCurve, error) {
+	curve := SkillCurve{}
+	for _, point := range points {
+		p := SkillCurvePoint{Wait: point.GetWait().AsDuration(), MaxSkill: point.GetMaxSkillDifference()}
+		if p.Wait <= 0 || (len(curve) > 0 && p.Wait <= curve[len(curve)-1].Wait) {
+			return nil, fmt.Errorf("skill curve points have to be ordered by positive wait times, got %s", p.Wait)
+		}
+		curve = append(curve, p)
+	}
+	return curve, nil
+}
+
+// At returns the skill window after waiting for wait, base is the window
+// without any wait.
+func (c SkillCurve) At(base float64, wait time.Duration) float64 {
+	previous := SkillCurvePoint{MaxSkill: base}
+	for _, point := range c {
+		if wait < point.Wait {
+			fraction := float64(wait-previous.Wait) / float64(point.Wait-previous.Wait)
+			return previous.MaxSkill + fraction*(point.MaxSkill-previous.MaxSkill)
+		}
+		previous = point
+	}
+	return previous.MaxSkill
+}
+
+// ticketSkillWindow returns the skill difference the ticket accepts at now,
+// the wider of the profile curve and the window of the ticket itself.
+func ticketSkillWindow(t *pb.Ticket, profile ProfileData, now time.Time) (float64, error) {
+	wait, err := ticket.GetWaitTimeFromTicket(t, now)
+	if err != nil {
+		return 0, err
+	}
+	// The curve only ever widens the window, an unlimited one stays unlimited.
+	window := math.Max(profile.MaxSkill, profile.SkillCurve.At(profile.MaxSkill, wait))
+	return ticket.GetSkillWindowFromTicket(t, window)
+}
+
+// acceptsAll returns whether every ticket of the match accepts the skill of
+// every other ticket within its own window.
+func acceptsAll(mt []*pb.Ticket, profile ProfileData, now time.Time) (bool, error) {
+	lowest, highest := math.Inf(1), math.Inf(-1)
+	for _, t := range mt {
+		lowest = math.Min(lowest, ticket.GetSkillFromTicket(t))
+		highest = math.Max(highest, ticket.GetSkillFromTicket(t))
+	}
+
+	for _, t := range mt {
+		window, err := ticketSkillWindow(t, profile, now)
+		if err != nil {
+			return false, fmt.Errorf("ticket %s, got %w", t.GetId(), err)
+		}
+		skill := ticket.GetSkillFromTicket(t)
+		if math.Max(highest-skill, skill-lowest) >= window {
+			return false, nil
+		}
+	}
+	return true, nil
+}
@@ -0,0 +1,176 @@
+package mmf
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	utils "sim/internal"
+	"sim/internal/ticket"
+
+	"open-match.dev/open-match/pkg/pb"
+)
+
+// Strategy forms match proposals from the tickets of a profile's pools.
+type Strategy interface {
+	MakeMatches(p *pb.MatchProfile, profile ProfileData, poolTickets map[string][]*pb.Ticket) ([]*pb.Match, error)
+}
+
+// StrategyFactory creates a strategy from the parameters set on the profile.
+type StrategyFactory func(params map[string]string) (Strategy, error)
+
+var strategies = map[string]StrategyFactory{}
+
+// GDefaultStrategy is used for profiles that do not name a strategy.
+var GDefaultStrategy = utils.StrategySkillWindow
+
+// RegisterStrategy makes a strategy available to profiles under the name. The
+// name and parameters have to be declared in utils.GStrategyParams, so the
+// director can check its profiles without the match function.
+func RegisterStrategy(name string, factory StrategyFactory) {
+	if _, ok := utils.GStrategyParams[name]; !ok {
+		panic(fmt.Sprintf("strategy %s is not declared in utils.GStrategyParams", name))
+	}
+	if _, ok := strategies[name]; ok {
+		panic(fmt.Sprintf("strategy %s registered twice", name))
+	}
+	strategies[name] = factory
+}
+
+// StrategyNames returns the registered strategies in alphabetical order.
+func StrategyNames() []string {
+	names := []string{}
+	for name := range strategies {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// NewStrategy creates the named strategy, an empty name creates the default one.
+func NewStrategy(name string, params map[string]string) (Strategy, error) {
+	if name == "" {
+		name = GDefaultStrategy
+	}
+	if err := utils.CheckStrategy(name, params); err != nil {
+		return nil, err
+	}
+	factory, ok := strategies[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown strategy %q, expected one of %s", name, strings.Join(StrategyNames(), ", "))
+	}
+	return factory(params)
+}
+
+func init() {
+	RegisterStrategy(utils.StrategyPools, func(params map[string]string) (Strategy, error) {
+		return poolsStrategy{}, nil
+	})
+	RegisterStrategy(utils.StrategyFIFO, func(params map[string]string) (Strategy, error) {
+		return fifoStrategy{}, nil
+	})
+	RegisterStrategy(utils.StrategySkillWindow, func(params map[string]string) (Strategy, error) {
+		mode := GPartitionMode
+		if name, ok := params[utils.PartitionParam]; ok {
+			var err error
+			if mode, err = ParsePartitionMode(name); err != nil {
+				return nil, err
+			}
+		}
+		return skillWindowStrategy{mode: mode}, nil
+	})
+	RegisterStrategy(utils.StrategyMinSpread, func(params map[string]string) (Strategy, error) {
+		return skillWindowStrategy{mode: PartitionMinSpread}, nil
+	})
+	RegisterStrategy(utils.StrategyLatencyFirst, func(params map[string]string) (Strategy, error) {
+		return latencyFirstStrategy{}, nil
+	})
+}
+
+// poolsStrategy takes the same number of players from every pool for each
+// match, in the order the tickets were returned.
+type poolsStrategy struct{}
+
+func (poolsStrategy) MakeMatches(p *pb.MatchProfile, profile ProfileData, poolTickets map[string][]*pb.Ticket) ([]*pb.Match, error) {
+	groups := [][]*pb.Ticket{}
+	for _, group := range poolGroups(poolTickets, profile.MaxPlayer) {
+		if fitsTeams(group, profile) {
+			groups = append(groups, group)
+		}
+	}
+	return formMatches(groups, profile)
+}
+
+// fifoStrategy matches the longest waiting tickets first.
+type fifoStrategy struct{}
+
+func (fifoStrategy) MakeMatches(p *pb.MatchProfile, profile ProfileData, poolTickets map[string][]*pb.Ticket) ([]*pb.Match, error) {
+	tickets := poolTickets[utils.GPoolName]
+	now := time.Now()
+	waits := make(map[*pb.Ticket]time.Duration, len(tickets))
+	for _, t := range tickets {
+		wait, err := ticket.GetWaitTimeFromTicket(t, now)
+		if err != nil {
+			return nil, err
+		}
+		waits[t] = wait
+	}
+	sort.SliceStable(tickets, func(i, j int) bool {
+		return waits[tickets[i]] > waits[tickets[j]]
+	})
+
+	groups, err := partitionGreedy(tickets, profile)
+	if err != nil {
+		return nil, err
+	}
+	return formMatches(groups, profile)
+}
+
+// skillWindowStrategy matches players of similar skill, the skill sorted
+// tickets are split into matches by the partition mode.
+type skillWindowStrategy struct {
+	mode PartitionMode
+}
+
+func (s skillWindowStrategy) MakeMatches(p *pb.MatchProfile, profile ProfileData, poolTickets map[string][]*pb.Ticket) ([]*pb.Match, error) {
+	tickets := poolTickets[utils.GPoolName]
+	sort.Slice(tickets, func(i, j int) bool {
+		return ticket.GetSkillFromTicket(tickets[i]) < ticket.GetSkillFromTicket(tickets[j])
+	})
+
+	groups, err := partition(tickets, profile, s.mode)
+	if err != nil {
+		return nil, err
+	}
+	return formMatches(groups, profile)
+}
+
+// latencyFirstStrategy matches the players with the lowest ping to the region
+// of the profile first, the skill difference is still enforced.
+type latencyFirstStrategy struct{}
+
+func (latencyFirstStrategy) MakeMatches(p *pb.MatchProfile, profile ProfileData, poolTickets map[string][]*pb.Ticket) ([]*pb.Match, error) {
+	if profile.Region == "" {
+		return nil, fmt.Errorf("latency_first needs a profile region")
+	}
+
+	tickets := poolTickets[utils.GPoolName]
+	latencies := make(map[*pb.Ticket]float64, len(tickets))
+	for _, t := range tickets {
+		latency, err := ticket.GetLatencyFromTicket(t, profile.Region)
+		if err != nil {
+			return nil, err
+		}
+		latencies[t] = latency
+	}
+	sort.SliceStable(tickets, func(i, j int) bool {
+		return latencies[tickets[i]] < latencies[tickets[j]]
+	})
+
+	groups, err := partitionGreedy(tickets, profile)
+	if err != nil {
+		return nil, err
+	}
+	return formMatches(groups, profile)
+}
@@ -0,0 +1,141 @@
+package mmf
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"sim/internal/ticket"
+	simproto "sim/proto"
+
+	"open-match.dev/open-match/pkg/pb"
+)
+
+// teamMember is a ticket of a match, parties are placed as a whole.
+type teamMember struct {
+	ticket *pb.Ticket
+	size   int
+	skill  float64
+}
+
+// splitTeams divides the tickets of a match into numTeams teams of equal size
+// and keeps the difference between the highest and lowest team skill small.
+// Teams are filled largest party first onto the weakest team that has room,
+// then pairs of equally sized tickets are swapped while that narrows the gap.
+func splitTeams(mt []*pb.Ticket, numTeams int) (*simproto.TeamLayout, error) {
+	players := countPlayers(mt)
+	if numTeams <= 0 || players%numTeams != 0 {
+		return nil, fmt.Errorf("can not split %d players into %d teams", players, numTeams)
+	}
+	capacity := players / numTeams
+
+	members := make([]teamMember, len(mt))
+	for index, t := range mt {
+		size := ticket.GetPartySizeFromTicket(t)
+		members[index] = teamMember{ticket: t, size: size, skill: ticket.GetSkillFromTicket(t) * float64(size)}
+	}
+	sort.SliceStable(members, func(i, j int) bool {
+		if members[i].size != members[j].size {
+			return members[i].size > members[j].size
+		}
+		return members[i].skill > members[j].skill
+	})
+
+	teams := make([][]teamMember, numTeams)
+	if !fillTeams(members, teams, capacity) {
+		return nil, fmt.Errorf("can not fit the parties of %d tickets into %d teams of %d", len(mt), numTeams, capacity)
+	}
+	balanceTeams(teams)
+
+	layout := &simproto.TeamLayout{}
+	for index, team := range teams {
+		t := &simproto.Team{Index: int32(index)}
+		for _, member := range team {
+			t.TicketIds = append(t.TicketIds, member.ticket.GetId())
+			t.Players += int32(member.size)
+			t.Skill += member.skill
+		}
+		layout.Teams = append(layout.Teams, t)
+	}
+	return layout, nil
+}
+
+// fillTeams places the members in order, trying the weakest team with room
+// first and backtracking when a party does not fit anywhere.
+func fillTeams(members []teamMember, teams [][]teamMember, capacity int) bool {
+	if len(members) == 0 {
+		return true
+	}
+	member := members[0]
+
+	order := make([]int, len(teams))
+	for index := range order {
+		order[index] = index
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return teamSkill(teams[order[i]]) < teamSkill(teams[order[j]])
+	})
+
+	triedEmpty := false
+	for _, index := range order {
+		if teamPlayers(teams[index])+member.size > capacity {
+			continue
+		}
+		// Empty teams are interchangeable, trying one of them is enough.
+		if len(teams[index]) == 0 {
+			if triedEmpty {
+				continue
+			}
+			triedEmpty = true
+		}
+
+		teams[index] = append(teams[index], member)
+		if fillTeams(members[1:], teams, capacity) {
+			return true
+		}
+		teams[index] = teams[index][:len(teams[index])-1]
+	}
+	return false
+}
+
+// balanceTeams swaps equally sized members between teams as long as a swap
+// narrows the skill gap between the two teams.
+func balanceTeams(teams [][]teamMember) {
+	for improved := true; improved; {
+		improved = false
+		for a := range teams {
+			for b := a + 1; b < len(teams); b++ {
+				for i := range teams[a] {
+					for j := range teams[b] {
+						if teams[a][i].size != teams[b][j].size {
+							continue
+						}
+						gap := math.Abs(teamSkill(teams[a]) - teamSkill(teams[b]))
+						delta := teams[a][i].skill - teams[b][j].skill
+						swapped := math.Abs(teamSkill(teams[a]) - teamSkill(teams[b]) - 2*delta)
+						if swapped < gap-1e-9 {
+							teams[a][i], teams[b][j] = teams[b][j], teams[a][i]
+							improved = true
+						}
+					}
+				}
+			}
+		}
+	}
+}
+
+func teamSkill(team []teamMember) float64 {
+	skill := 0.0
+	for _, member := range team {
+		skill += member.skill
+	}
+	return skill
+}
+
+func teamPlayers(team []teamMember) int {
+	players := 0
+	for _, member := range team {
+		players += member.size
+	}
+	return players
+}
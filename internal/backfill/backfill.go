@@ -0,0 +1,54 @@
+package backfill
+
+import (
+	"fmt"
+	"math"
+
+	utils "sim/internal"
+	simproto "sim/proto"
+
+	"google.golang.org/protobuf/types/known/anypb"
+	"open-match.dev/open-match/pkg/pb"
+)
+
+// New creates a backfill for a game with open slots. The search fields are
+// taken from the pool, so the match function finds the backfill with the same
+// pool it queries the tickets with. Only the skill of the game is searchable,
+// other range filters of the pool are left unset.
+func New(pool *pb.Pool, state *simproto.BackfillState) *pb.Backfill {
+	b := &pb.Backfill{
+		SearchFields: &pb.SearchFields{
+			DoubleArgs: make(map[string]float64),
+		},
+		Extensions: make(map[string]*anypb.Any),
+	}
+	for _, filter := range pool.GetTagPresentFilters() {
+		b.SearchFields.Tags = append(b.SearchFields.Tags, filter.GetTag())
+	}
+	for _, filter := range pool.GetDoubleRangeFilters() {
+		if filter.GetDoubleArg() == utils.GSkillArg {
+			b.SearchFields.DoubleArgs[utils.GSkillArg] = math.Min(math.Max(state.GetSkill(), filter.GetMin()), filter.GetMax())
+		}
+	}
+	SetState(b, state)
+	return b
+}
+
+// GetState reads the state of the game server behind the backfill.
+func GetState(b *pb.Backfill) (*simproto.BackfillState, error) {
+	state, err := utils.GetMessage(b.GetExtensions(), utils.BackfillState)
+	if err != nil {
+		return nil, fmt.Errorf("backfill %s has no state, got %w", b.GetId(), err)
+	}
+	if state.GetOpenSlots() < 0 || state.GetOpenSlots() > state.GetMaxPlayers() {
+		return nil, fmt.Errorf("backfill %s has %d open slots for %d players", b.GetId(), state.GetOpenSlots(), state.GetMaxPlayers())
+	}
+	return state, nil
+}
+
+func SetState(b *pb.Backfill, state *simproto.BackfillState) {
+	if b.Extensions == nil {
+		b.Extensions = make(map[string]*anypb.Any)
+	}
+	utils.SetMessage(b.Extensions, utils.BackfillState, state)
+}
@@ -0,0 +1,240 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// ExtensionValue lists the types an extension key can hold.
+type ExtensionValue interface {
+	int | float64 | bool | string | []string | time.Duration | time.Time
+}
+
+// ErrMissingExtension is returned when an extension is not set.
+var ErrMissingExtension = errors.New("missing extension")
+
+// Key is a declared, typed extension key. Keys are namespaced, for example
+// "sim.profile.max_players", and can only be created through DeclareKey so a
+// name is never used with two different types.
+type Key[T ExtensionValue] struct {
+	name string
+}
+
+func (k Key[T]) Name() string {
+	return k.name
+}
+
+func (k Key[T]) String() string {
+	return k.name
+}
+
+// KeyFamily declares a group of keys that share a prefix, for example one ping
+// per region.
+type KeyFamily[T ExtensionValue] struct {
+	prefix string
+}
+
+// Key returns the key of the family for the given suffix.
+func (f KeyFamily[T]) Key(suffix string) Key[T] {
+	return Key[T]{name: f.prefix + suffix}
+}
+
+// Suffix returns the suffix of an extension name that belongs to the family.
+func (f KeyFamily[T]) Suffix(name string) (string, bool) {
+	suffix, ok := strings.CutPrefix(name, f.prefix)
+	return suffix, ok && suffix != ""
+}
+
+var (
+	registryMu sync.Mutex
+	registry   = map[string]string{}
+)
+
+// DeclareKey registers a key in the namespace. Declaring the same name twice
+// is a programming error and panics.
+func DeclareKey[T ExtensionValue](namespace string, name string) Key[T] {
+	full := namespace + "." + name
+	register(full, typeName[T]())
+	return Key[T]{name: full}
+}
+
+// DeclareKeyFamily registers a group of keys sharing the namespace and prefix.
+func DeclareKeyFamily[T ExtensionValue](namespace string, prefix string) KeyFamily[T] {
+	full := namespace + "." + prefix + "."
+	register(full+"*", typeName[T]())
+	return KeyFamily[T]{prefix: full}
+}
+
+// RegisteredKeys returns every declared key name with the type it holds.
+func RegisteredKeys() map[string]string {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	keys := make(map[string]string, len(registry))
+	for name, kind := range registry {
+		keys[name] = kind
+	}
+	return keys
+}
+
+func register(name string, kind string) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	if existing, ok := registry[name]; ok {
+		panic(fmt.Sprintf("extension key %s declared twice, as %s and %s", name, existing, kind))
+	}
+	registry[name] = kind
+}
+
+func typeName[T ExtensionValue]() string {
+	var zero T
+	return fmt.Sprintf("%T", zero)
+}
+
+// MessageKey is a declared extension key holding a protobuf message, like Key
+// it can only be created through its declaration.
+type MessageKey[M proto.Message] struct {
+	name string
+}
+
+func (k MessageKey[M]) Name() string {
+	return k.name
+}
+
+func (k MessageKey[M]) String() string {
+	return k.name
+}
+
+// DeclareMessageKey registers a message key in the namespace. Declaring the
+// same name twice panics, whatever the type of the other key.
+func DeclareMessageKey[M proto.Message](namespace string, name string) MessageKey[M] {
+	full := namespace + "." + name
+	var zero M
+	register(full, string(zero.ProtoReflect().Descriptor().FullName()))
+	return MessageKey[M]{name: full}
+}
+
+// SetMessage stores the message under the key.
+func SetMessage[M proto.Message](extensions map[string]*anypb.Any, key MessageKey[M], m M) {
+	extensions[key.name] = MustAny(m)
+}
+
+// GetMessage reads the message stored under the key. It fails if the
+// extension is missing or holds another message.
+func GetMessage[M proto.Message](extensions map[string]*anypb.Any, key MessageKey[M]) (M, error) {
+	var zero M
+	a, ok := extensions[key.name]
+	if !ok {
+		return zero, fmt.Errorf("%w %s", ErrMissingExtension, key.name)
+	}
+
+	m := zero.ProtoReflect().New().Interface().(M)
+	if err := a.UnmarshalTo(m); err != nil {
+		return zero, fmt.Errorf("extension %s is not a %s, got %w", key.name, m.ProtoReflect().Descriptor().FullName(), err)
+	}
+	return m, nil
+}
+
+// Set stores the value under the key.
+func Set[T ExtensionValue](extensions map[string]*anypb.Any, key Key[T], value T) {
+	var m proto.Message
+	switch v := any(value).(type) {
+	case int:
+		m = wrapperspb.Int64(int64(v))
+	case float64:
+		m = wrapperspb.Double(v)
+	case bool:
+		m = wrapperspb.Bool(v)
+	case string:
+		m = wrapperspb.String(v)
+	case []string:
+		list := &structpb.ListValue{}
+		for _, s := range v {
+			list.Values = append(list.Values, structpb.NewStringValue(s))
+		}
+		m = list
+	case time.Duration:
+		m = durationpb.New(v)
+	case time.Time:
+		m = timestamppb.New(v)
+	}
+	extensions[key.name] = MustAny(m)
+}
+
+// Get reads the value stored under the key. It fails if the extension is
+// missing or holds another type.
+func Get[T ExtensionValue](extensions map[string]*anypb.Any, key Key[T]) (T, error) {
+	var result T
+	a, ok := extensions[key.name]
+	if !ok {
+		return result, fmt.Errorf("%w %s", ErrMissingExtension, key.name)
+	}
+
+	var err error
+	switch r := any(&result).(type) {
+	case *int:
+		m := &wrapperspb.Int64Value{}
+		err = a.UnmarshalTo(m)
+		*r = int(m.GetValue())
+	case *float64:
+		m := &wrapperspb.DoubleValue{}
+		err = a.UnmarshalTo(m)
+		*r = m.GetValue()
+	case *bool:
+		m := &wrapperspb.BoolValue{}
+		err = a.UnmarshalTo(m)
+		*r = m.GetValue()
+	case *string:
+		m := &wrapperspb.StringValue{}
+		err = a.UnmarshalTo(m)
+		*r = m.GetValue()
+	case *[]string:
+		m := &structpb.ListValue{}
+		err = a.UnmarshalTo(m)
+		for _, v := range m.GetValues() {
+			s, ok := v.GetKind().(*structpb.Value_StringValue)
+			if !ok {
+				return result, fmt.Errorf("extension %s holds a non string list entry", key.name)
+			}
+			*r = append(*r, s.StringValue)
+		}
+	case *time.Duration:
+		m := &durationpb.Duration{}
+		err = a.UnmarshalTo(m)
+		*r = m.AsDuration()
+	case *time.Time:
+		m := &timestamppb.Timestamp{}
+		err = a.UnmarshalTo(m)
+		*r = m.AsTime()
+	}
+	if err != nil {
+		return result, fmt.Errorf("extension %s is not a %T, got %w", key.name, result, err)
+	}
+	return result, nil
+}
+
+// GetOr reads the value stored under the key, returning fallback if it is not
+// set. A value of the wrong type is still an error.
+func GetOr[T ExtensionValue](extensions map[string]*anypb.Any, key Key[T], fallback T) (T, error) {
+	if !Has(extensions, key) {
+		return fallback, nil
+	}
+	return Get(extensions, key)
+}
+
+// Has returns whether the key is set.
+func Has(extensions map[string]*anypb.Any, key interface{ Name() string }) bool {
+	_, ok := extensions[key.Name()]
+	return ok
+}
@@ -0,0 +1,107 @@
+package utils
+
+import (
+	"testing"
+	"time"
+
+	simproto "sim/proto"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+var (
+	testInt      = DeclareKey[int]("test", "int")
+	testFloat    = DeclareKey[float64]("test", "float")
+	testBool     = DeclareKey[bool]("test", "bool")
+	testString   = DeclareKey[string]("test", "string")
+	testList     = DeclareKey[[]string]("test", "list")
+	testDuration = DeclareKey[time.Duration]("test", "duration")
+	testFamily   = DeclareKeyFamily[float64]("test", "family")
+	testSettings = DeclareMessageKey[*simproto.ProfileSettings]("test", "settings")
+	testQuality  = DeclareMessageKey[*simproto.MatchQuality]("test", "quality")
+)
+
+func TestExtensionRoundTrip(t *testing.T) {
+	require := require.New(t)
+	extensions := map[string]*anypb.Any{}
+
+	Set(extensions, testInt, 16)
+	Set(extensions, testFloat, 2.5)
+	Set(extensions, testBool, true)
+	Set(extensions, testString, "europe")
+	Set(extensions, testList, []string{"a", "b"})
+	Set(extensions, testDuration, 90*time.Second)
+	Set(extensions, testFamily.Key("us"), 40)
+
+	i, err := Get(extensions, testInt)
+	require.NoError(err)
+	require.Equal(16, i)
+	f, err := Get(extensions, testFloat)
+	require.NoError(err)
+	require.Equal(2.5, f)
+	b, err := Get(extensions, testBool)
+	require.NoError(err)
+	require.True(b)
+	s, err := Get(extensions, testString)
+	require.NoError(err)
+	require.Equal("europe", s)
+	l, err := Get(extensions, testList)
+	require.NoError(err)
+	require.Equal([]string{"a", "b"}, l)
+	d, err := Get(extensions, testDuration)
+	require.NoError(err)
+	require.Equal(90*time.Second, d)
+
+	region, ok := testFamily.Suffix(testFamily.Key("us").Name())
+	require.True(ok)
+	require.Equal("us", region)
+	_, ok = testFamily.Suffix(testInt.Name())
+	require.False(ok)
+
+	require.False(Has(extensions, testSettings))
+	SetMessage(extensions, testSettings, &simproto.ProfileSettings{Region: "europe", MaxPlayers: 16})
+	require.True(Has(extensions, testSettings))
+	require.Contains(extensions, "test.settings")
+
+	settings, err := GetMessage(extensions, testSettings)
+	require.NoError(err)
+	require.Equal("europe", settings.GetRegion())
+	require.EqualValues(16, settings.GetMaxPlayers())
+
+	require.Equal("int", RegisteredKeys()["test.int"])
+	require.Equal("float64", RegisteredKeys()["test.family.*"])
+	require.Equal("sim.ProfileSettings", RegisteredKeys()["test.settings"])
+	require.Equal("sim.PlayerProfile", RegisteredKeys()[TicketPlayerProfile.Name()])
+}
+
+func TestExtensionErrors(t *testing.T) {
+	require := require.New(t)
+	extensions := map[string]*anypb.Any{}
+
+	_, err := Get(extensions, testInt)
+	require.ErrorIs(err, ErrMissingExtension)
+	_, err = GetMessage(extensions, testSettings)
+	require.ErrorIs(err, ErrMissingExtension)
+
+	fallback, err := GetOr(extensions, testInt, 4)
+	require.NoError(err)
+	require.Equal(4, fallback)
+
+	// Reading a value through a key of another type fails instead of
+	// returning a zero value.
+	Set(extensions, testString, "16")
+	extensions[testInt.Name()] = extensions[testString.Name()]
+	_, err = Get(extensions, testInt)
+	require.Error(err)
+	_, err = GetOr(extensions, testInt, 4)
+	require.Error(err)
+
+	SetMessage(extensions, testQuality, &simproto.MatchQuality{Score: 1})
+	extensions[testSettings.Name()] = extensions[testQuality.Name()]
+	_, err = GetMessage(extensions, testSettings)
+	require.ErrorContains(err, "is not a sim.ProfileSettings")
+
+	require.Panics(func() { DeclareKey[float64]("test", "int") }, "Keys can only be declared once")
+	require.Panics(func() { DeclareMessageKey[*simproto.MatchQuality]("test", "settings") }, "Keys can only be declared once")
+}
@@ -0,0 +1,183 @@
+package geo
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	earthRadiusKm = 6371.0
+	// Light travels roughly 200 km per millisecond in fiber.
+	fiberKmPerMs = 200.0
+)
+
+// Coordinates is a position on the globe in degrees.
+type Coordinates struct {
+	Latitude  float64 `yaml:"lat"`
+	Longitude float64 `yaml:"lon"`
+}
+
+// DistanceKm returns the great circle distance between two coordinates.
+func DistanceKm(a Coordinates, b Coordinates) float64 {
+	lat1 := a.Latitude * math.Pi / 180
+	lat2 := b.Latitude * math.Pi / 180
+	dLat := lat2 - lat1
+	dLon := (b.Longitude - a.Longitude) * math.Pi / 180
+
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadiusKm * math.Asin(math.Min(1, math.Sqrt(h)))
+}
+
+// Datacenter is the location of the game servers of a region.
+type Datacenter struct {
+	Region   string      `yaml:"region"`
+	Location Coordinates `yaml:"location"`
+}
+
+// PlayerArea is a part of the world players are placed in.
+type PlayerArea struct {
+	Name     string      `yaml:"name"`
+	Location Coordinates `yaml:"location"`
+	// Players are spread around Location within this radius.
+	RadiusKm float64 `yaml:"radius_km"`
+	// Relative share of players living in the area.
+	Weight float64 `yaml:"weight"`
+}
+
+// LatencyModel derives the ping of a player towards every datacenter from the
+// distance between them. All pings of a player share the same last mile, so
+// they are correlated the way real pings are.
+type LatencyModel struct {
+	Datacenters []Datacenter `yaml:"datacenters"`
+	Areas       []PlayerArea `yaml:"areas"`
+	// How much longer the routed path is than the great circle distance.
+	RouteFactor float64 `yaml:"route_factor"`
+	// Mean and spread of the round trip added by the player's own connection.
+	LastMileMs     float64 `yaml:"last_mile_ms"`
+	LastMileStdDev float64 `yaml:"last_mile_stddev"`
+	// Spread of the per measurement jitter.
+	JitterMs float64 `yaml:"jitter_ms"`
+	// Chance that a measurement hits a packet loss spike and how much it adds.
+	SpikeChance float64 `yaml:"spike_chance"`
+	SpikeMs     float64 `yaml:"spike_ms"`
+}
+
+// GLatencyModel replaces the ping distributions when set.
+var GLatencyModel *LatencyModel
+
+// DefaultLatencyModel places players across Europe and the Americas with
+// datacenters in Frankfurt and Virginia.
+func DefaultLatencyModel() *LatencyModel {
+	return &LatencyModel{
+		Datacenters: []Datacenter{
+			{Region: "europe", Location: Coordinates{Latitude: 50.11, Longitude: 8.68}},
+			{Region: "us", Location: Coordinates{Latitude: 38.95, Longitude: -77.45}},
+		},
+		Areas: []PlayerArea{
+			{Name: "europe", Location: Coordinates{Latitude: 50, Longitude: 10}, RadiusKm: 1500, Weight: 0.5},
+			{Name: "north_america", Location: Coordinates{Latitude: 40, Longitude: -95}, RadiusKm: 2000, Weight: 0.4},
+			{Name: "south_america", Location: Coordinates{Latitude: -15, Longitude: -55}, RadiusKm: 1500, Weight: 0.05},
+			{Name: "asia", Location: Coordinates{Latitude: 35, Longitude: 120}, RadiusKm: 2000, Weight: 0.05},
+		},
+		RouteFactor:    1.5,
+		LastMileMs:     15,
+		LastMileStdDev: 10,
+		JitterMs:       3,
+		SpikeChance:    0.02,
+		SpikeMs:        150,
+	}
+}
+
+// LoadLatencyModel reads a YAML or JSON latency model, unset values are taken
+// from DefaultLatencyModel.
+func LoadLatencyModel(path string) (*LatencyModel, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	model := DefaultLatencyModel()
+	if err := yaml.Unmarshal(data, model); err != nil {
+		return nil, fmt.Errorf("failed to parse %s, got %w", path, err)
+	}
+	if err := model.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid latency model in %s, got %w", path, err)
+	}
+	return model, nil
+}
+
+func (m *LatencyModel) Validate() error {
+	if len(m.Datacenters) == 0 {
+		return fmt.Errorf("no datacenters configured")
+	}
+	total := 0.0
+	for _, area := range m.Areas {
+		if area.Weight < 0 || area.RadiusKm < 0 {
+			return fmt.Errorf("invalid area %s", area.Name)
+		}
+		total += area.Weight
+	}
+	if total <= 0 {
+		return fmt.Errorf("no player areas with weight configured")
+	}
+	return nil
+}
+
+// PlacePlayer picks a random location for a new player.
+func (m *LatencyModel) PlacePlayer(r *rand.Rand) Coordinates {
+	total := 0.0
+	for _, area := range m.Areas {
+		total += area.Weight
+	}
+
+	target := r.Float64() * total
+	area := m.Areas[len(m.Areas)-1]
+	for _, a := range m.Areas {
+		if target < a.Weight {
+			area = a
+			break
+		}
+		target -= a.Weight
+	}
+
+	// Spread players evenly over the disc around the center of the area.
+	distance := area.RadiusKm * math.Sqrt(r.Float64())
+	bearing := r.Float64() * 2 * math.Pi
+	return offset(area.Location, distance, bearing)
+}
+
+// Pings measures the round trip time from the location towards every
+// datacenter, keyed by region.
+func (m *LatencyModel) Pings(r *rand.Rand, location Coordinates) map[string]float64 {
+	lastMile := math.Max(1, m.LastMileMs+r.NormFloat64()*m.LastMileStdDev)
+
+	pings := make(map[string]float64, len(m.Datacenters))
+	for _, dc := range m.Datacenters {
+		propagation := 2 * DistanceKm(location, dc.Location) * m.RouteFactor / fiberKmPerMs
+		ping := propagation + lastMile + math.Abs(r.NormFloat64()*m.JitterMs)
+		if r.Float64() < m.SpikeChance {
+			ping += r.ExpFloat64() * m.SpikeMs
+		}
+		pings[dc.Region] = ping
+	}
+	return pings
+}
+
+// offset moves the coordinates distanceKm along the bearing (in radians).
+func offset(from Coordinates, distanceKm float64, bearing float64) Coordinates {
+	lat1 := from.Latitude * math.Pi / 180
+	lon1 := from.Longitude * math.Pi / 180
+	angular := distanceKm / earthRadiusKm
+
+	lat2 := math.Asin(math.Sin(lat1)*math.Cos(angular) + math.Cos(lat1)*math.Sin(angular)*math.Cos(bearing))
+	lon2 := lon1 + math.Atan2(math.Sin(bearing)*math.Sin(angular)*math.Cos(lat1), math.Cos(angular)-math.Sin(lat1)*math.Sin(lat2))
+
+	return Coordinates{
+		Latitude:  lat2 * 180 / math.Pi,
+		Longitude: math.Mod(lon2*180/math.Pi+540, 360) - 180,
+	}
+}
@@ -0,0 +1,43 @@
+package geo
+
+import (
+	"testing"
+
+	"sim/internal/random"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDistance(t *testing.T) {
+	require := require.New(t)
+
+	frankfurt := Coordinates{Latitude: 50.11, Longitude: 8.68}
+	virginia := Coordinates{Latitude: 38.95, Longitude: -77.45}
+	require.InDelta(6530, DistanceKm(frankfurt, virginia), 50)
+	require.InDelta(0, DistanceKm(frankfurt, frankfurt), 0.001)
+
+	moved := offset(frankfurt, 500, 0)
+	require.InDelta(500, DistanceKm(frankfurt, moved), 1)
+}
+
+func TestPingsFollowDistance(t *testing.T) {
+	require := require.New(t)
+
+	model := DefaultLatencyModel()
+	model.SpikeChance = 0
+	r := random.New(1)
+
+	// A player next to Frankfurt always prefers europe and is far from us.
+	berlin := Coordinates{Latitude: 52.52, Longitude: 13.40}
+	for i := 0; i < 100; i++ {
+		pings := model.Pings(r, berlin)
+		require.Less(pings["europe"], pings["us"])
+		require.Greater(pings["us"]-pings["europe"], 50.0)
+	}
+
+	for i := 0; i < 100; i++ {
+		location := model.PlacePlayer(r)
+		require.True(location.Latitude >= -90 && location.Latitude <= 90)
+		require.True(location.Longitude >= -180 && location.Longitude <= 180)
+	}
+}
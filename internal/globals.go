@@ -15,21 +15,18 @@ var (
 	GRegions      = []string{"europe", "us"}
 	GPasswordName = "password"
 
-	GPoolName           = "all"
-	GSkillArg           = "skill"
-	GTrustedArg         = "trusted"
-	GPasswordArg        = "password"
-	GLatencyArg         = "latency"
-	GBeginnerName       = "beginner"
-	GMaxPlayersKey      = "max_players"
-	GBestRegionKey      = "best_region"
-	GProfileRegion      = "profile_region"
-	GMaxSkillDifference = "match_skill"
-	GSimulationMode     = All
+	GPoolName       = "all"
+	GSkillArg       = "skill"
+	GTrustedArg     = "trusted"
+	GPasswordArg    = "password"
+	GLatencyArg     = "latency"
+	GPartySizeArg   = "party_size"
+	GBeginnerName   = "beginner"
+	GSimulationMode = All
 
 	GMaxLatency = 1000
 	GMaxSkill   = 50
 
-	GCurrentNumTickets = "num_tickets"
-	GCurrentNumMatches = "num_matches"
+	// Extension read by the default evaluator of Open Match.
+	GEvaluationInputKey = "evaluation_input"
 )
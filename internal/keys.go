@@ -0,0 +1,36 @@
+package utils
+
+import (
+	simproto "sim/proto"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// Namespaces of the extension keys.
+const (
+	TicketNamespace     = "sim.ticket"
+	ProfileNamespace    = "sim.profile"
+	MatchNamespace      = "sim.match"
+	AssignmentNamespace = "sim.assignment"
+	BackfillNamespace   = "sim.backfill"
+)
+
+var (
+	// TicketPlayerProfile holds everything about the client that is not
+	// searchable, like its pings and party members.
+	TicketPlayerProfile = DeclareMessageKey[*simproto.PlayerProfile](TicketNamespace, "player_profile")
+	// ProfileSettings configures the match function for a match profile.
+	ProfileSettings = DeclareMessageKey[*simproto.ProfileSettings](ProfileNamespace, "settings")
+	// MatchQuality describes a match proposal.
+	MatchQuality = DeclareMessageKey[*simproto.MatchQuality](MatchNamespace, "quality")
+	// MatchTeams splits the tickets of a match into teams.
+	MatchTeams = DeclareMessageKey[*simproto.TeamLayout](MatchNamespace, "teams")
+	// AssignmentTeam tells the game server which team the tickets of an
+	// assignment play on.
+	AssignmentTeam = DeclareMessageKey[*simproto.Team](AssignmentNamespace, "team")
+	// AssignmentTime is when the director assigned the tickets, so that queue
+	// times do not depend on when the client got to watch its ticket.
+	AssignmentTime = DeclareMessageKey[*timestamppb.Timestamp](AssignmentNamespace, "time")
+	// BackfillState tracks the open slots of the game server behind a backfill.
+	BackfillState = DeclareMessageKey[*simproto.BackfillState](BackfillNamespace, "state")
+)
@@ -0,0 +1,363 @@
+package random
+
+import (
+	"encoding/csv"
+	"fmt"
+	"math"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Distribution draws random values, for example the skill of a player or its
+// ping towards a region.
+type Distribution interface {
+	Sample(r *rand.Rand) float64
+}
+
+// Uniform draws values evenly between Min and Max.
+type Uniform struct {
+	Min float64
+	Max float64
+}
+
+func (d Uniform) Sample(r *rand.Rand) float64 {
+	return d.Min + r.Float64()*(d.Max-d.Min)
+}
+
+// Normal draws values from a normal distribution.
+type Normal struct {
+	Mean   float64
+	StdDev float64
+}
+
+func (d Normal) Sample(r *rand.Rand) float64 {
+	return d.Mean + r.NormFloat64()*d.StdDev
+}
+
+// LogNormal draws values whose logarithm is normally distributed, which fits
+// long tailed values like pings.
+type LogNormal struct {
+	Mu    float64
+	Sigma float64
+}
+
+func (d LogNormal) Sample(r *rand.Rand) float64 {
+	return math.Exp(d.Mu + r.NormFloat64()*d.Sigma)
+}
+
+// Bimodal mixes two distributions, drawing from First with probability Weight.
+type Bimodal struct {
+	First  Distribution
+	Second Distribution
+	Weight float64
+}
+
+func (d Bimodal) Sample(r *rand.Rand) float64 {
+	if r.Float64() < d.Weight {
+		return d.First.Sample(r)
+	}
+	return d.Second.Sample(r)
+}
+
+// Bin is a single bucket of a histogram.
+type Bin struct {
+	Lower  float64 `yaml:"lower"`
+	Upper  float64 `yaml:"upper"`
+	Weight float64 `yaml:"weight"`
+}
+
+// Empirical draws values from a histogram, picking a bin by its weight and a
+// value uniformly inside the bin.
+type Empirical struct {
+	bins       []Bin
+	cumulative []float64
+}
+
+func NewEmpirical(bins []Bin) (*Empirical, error) {
+	d := &Empirical{}
+	total := 0.0
+	for _, bin := range bins {
+		if bin.Weight < 0 || bin.Upper < bin.Lower {
+			return nil, fmt.Errorf("invalid histogram bin %+v", bin)
+		}
+		if bin.Weight == 0 {
+			continue
+		}
+		total += bin.Weight
+		d.bins = append(d.bins, bin)
+		d.cumulative = append(d.cumulative, total)
+	}
+	if total == 0 {
+		return nil, fmt.Errorf("histogram has no weight")
+	}
+	return d, nil
+}
+
+func (d *Empirical) Sample(r *rand.Rand) float64 {
+	target := r.Float64() * d.cumulative[len(d.cumulative)-1]
+	index := sort.SearchFloat64s(d.cumulative, target)
+	if index >= len(d.bins) {
+		index = len(d.bins) - 1
+	}
+	return Uniform{Min: d.bins[index].Lower, Max: d.bins[index].Upper}.Sample(r)
+}
+
+// Clamped keeps the values of a distribution within Min and Max.
+type Clamped struct {
+	Distribution
+	Min float64
+	Max float64
+}
+
+func (d Clamped) Sample(r *rand.Rand) float64 {
+	return math.Max(d.Min, math.Min(d.Max, d.Distribution.Sample(r)))
+}
+
+// DistributionSpec is the configuration of a single distribution.
+type DistributionSpec struct {
+	// One of uniform, normal, lognormal, bimodal or empirical.
+	Type   string  `yaml:"type"`
+	Min    float64 `yaml:"min"`
+	Max    float64 `yaml:"max"`
+	Mean   float64 `yaml:"mean"`
+	StdDev float64 `yaml:"stddev"`
+	Mu     float64 `yaml:"mu"`
+	Sigma  float64 `yaml:"sigma"`
+	// Bimodal mixes First and Second, drawing from First with probability Weight.
+	First  *DistributionSpec `yaml:"first"`
+	Second *DistributionSpec `yaml:"second"`
+	Weight float64           `yaml:"weight"`
+	// Empirical histograms are either inlined or read from a CSV file with
+	// lower,upper,weight rows.
+	Bins []Bin  `yaml:"bins"`
+	File string `yaml:"file"`
+	// Optional bounds applied to every sample.
+	Clamp *Bin `yaml:"clamp"`
+}
+
+// Build creates the distribution, relative histogram files are resolved
+// against baseDir.
+func (s DistributionSpec) Build(baseDir string) (Distribution, error) {
+	var d Distribution
+	switch s.Type {
+	case "uniform":
+		if s.Min > s.Max {
+			return nil, fmt.Errorf("uniform distribution has min %v above max %v", s.Min, s.Max)
+		}
+		d = Uniform{Min: s.Min, Max: s.Max}
+	case "normal":
+		if s.StdDev < 0 {
+			return nil, fmt.Errorf("normal distribution has negative stddev %v", s.StdDev)
+		}
+		d = Normal{Mean: s.Mean, StdDev: s.StdDev}
+	case "lognormal":
+		if s.Sigma < 0 {
+			return nil, fmt.Errorf("lognormal distribution has negative sigma %v", s.Sigma)
+		}
+		d = LogNormal{Mu: s.Mu, Sigma: s.Sigma}
+	case "bimodal":
+		if s.First == nil || s.Second == nil {
+			return nil, fmt.Errorf("bimodal distribution needs first and second")
+		}
+		if s.Weight < 0 || s.Weight > 1 {
+			return nil, fmt.Errorf("bimodal distribution needs a weight between 0 and 1, got %v", s.Weight)
+		}
+		first, err := s.First.Build(baseDir)
+		if err != nil {
+			return nil, err
+		}
+		second, err := s.Second.Build(baseDir)
+		if err != nil {
+			return nil, err
+		}
+		d = Bimodal{First: first, Second: second, Weight: s.Weight}
+	case "empirical":
+		bins := s.Bins
+		if s.File != "" {
+			path := s.File
+			if !filepath.IsAbs(path) {
+				path = filepath.Join(baseDir, path)
+			}
+			var err error
+			if bins, err = readHistogram(path); err != nil {
+				return nil, err
+			}
+		}
+		empirical, err := NewEmpirical(bins)
+		if err != nil {
+			return nil, err
+		}
+		d = empirical
+	default:
+		return nil, fmt.Errorf("unknown distribution type %q", s.Type)
+	}
+
+	if s.Clamp != nil {
+		if s.Clamp.Lower > s.Clamp.Upper {
+			return nil, fmt.Errorf("clamp has lower %v above upper %v", s.Clamp.Lower, s.Clamp.Upper)
+		}
+		d = Clamped{Distribution: d, Min: s.Clamp.Lower, Max: s.Clamp.Upper}
+	}
+	return d, nil
+}
+
+// DistributionSet picks a distribution by game mode and region. The most
+// specific one wins: one configured for both the mode and the region, then one
+// for the mode, then one for the region and finally Default.
+type DistributionSet struct {
+	Default     Distribution
+	Modes       map[string]Distribution
+	Regions     map[string]Distribution
+	ModeRegions map[string]map[string]Distribution
+}
+
+func (s DistributionSet) Pick(mode string, region string) Distribution {
+	if d, ok := s.ModeRegions[mode][region]; ok {
+		return d
+	}
+	if d, ok := s.Modes[mode]; ok {
+		return d
+	}
+	if d, ok := s.Regions[region]; ok {
+		return d
+	}
+	return s.Default
+}
+
+// DistributionSetSpec is the configuration of a DistributionSet.
+type DistributionSetSpec struct {
+	Default     *DistributionSpec                      `yaml:"default"`
+	Modes       map[string]DistributionSpec            `yaml:"modes"`
+	Regions     map[string]DistributionSpec            `yaml:"regions"`
+	ModeRegions map[string]map[string]DistributionSpec `yaml:"mode_regions"`
+}
+
+func (s DistributionSetSpec) build(baseDir string, fallback Distribution) (DistributionSet, error) {
+	set := DistributionSet{
+		Default:     fallback,
+		Modes:       make(map[string]Distribution),
+		Regions:     make(map[string]Distribution),
+		ModeRegions: make(map[string]map[string]Distribution),
+	}
+	if s.Default != nil {
+		d, err := s.Default.Build(baseDir)
+		if err != nil {
+			return set, fmt.Errorf("default: %w", err)
+		}
+		set.Default = d
+	}
+	for mode, spec := range s.Modes {
+		d, err := spec.Build(baseDir)
+		if err != nil {
+			return set, fmt.Errorf("mode %s: %w", mode, err)
+		}
+		set.Modes[mode] = d
+	}
+	for region, spec := range s.Regions {
+		d, err := spec.Build(baseDir)
+		if err != nil {
+			return set, fmt.Errorf("region %s: %w", region, err)
+		}
+		set.Regions[region] = d
+	}
+	for mode, regions := range s.ModeRegions {
+		set.ModeRegions[mode] = make(map[string]Distribution)
+		for region, spec := range regions {
+			d, err := spec.Build(baseDir)
+			if err != nil {
+				return set, fmt.Errorf("mode %s region %s: %w", mode, region, err)
+			}
+			set.ModeRegions[mode][region] = d
+		}
+	}
+	return set, nil
+}
+
+// DistributionConfig is the file format of the distribution configuration.
+type DistributionConfig struct {
+	Skill DistributionSetSpec `yaml:"skill"`
+	Ping  DistributionSetSpec `yaml:"ping"`
+}
+
+var (
+	// GSkillDistributions is used by FindSkill.
+	GSkillDistributions = DistributionSet{Default: Uniform{Min: 0, Max: 500}}
+	// GPingDistributions is used by FindRegionRandom.
+	GPingDistributions = DistributionSet{Default: Uniform{Min: 0, Max: 500}}
+)
+
+// LoadDistributions reads a YAML or JSON distribution configuration and
+// replaces the skill and ping distributions with it.
+func LoadDistributions(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	config := DistributionConfig{}
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return fmt.Errorf("failed to parse %s, got %w", path, err)
+	}
+
+	baseDir := filepath.Dir(path)
+	skill, err := config.Skill.build(baseDir, GSkillDistributions.Default)
+	if err != nil {
+		return fmt.Errorf("invalid skill distribution in %s, got %w", path, err)
+	}
+	ping, err := config.Ping.build(baseDir, GPingDistributions.Default)
+	if err != nil {
+		return fmt.Errorf("invalid ping distribution in %s, got %w", path, err)
+	}
+
+	GSkillDistributions = skill
+	GPingDistributions = ping
+	return nil
+}
+
+func readHistogram(path string) ([]Bin, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	rows, err := csv.NewReader(f).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read histogram %s, got %w", path, err)
+	}
+
+	bins := []Bin{}
+	for line, row := range rows {
+		if len(row) != 3 {
+			return nil, fmt.Errorf("histogram %s line %d: expected lower,upper,weight", path, line+1)
+		}
+		bin, err := parseBin(row)
+		if err != nil {
+			// The first row may be a header.
+			if line == 0 {
+				continue
+			}
+			return nil, fmt.Errorf("histogram %s line %d: %w", path, line+1, err)
+		}
+		bins = append(bins, bin)
+	}
+	return bins, nil
+}
+
+func parseBin(row []string) (Bin, error) {
+	values := [3]float64{}
+	for i := range row {
+		value, err := strconv.ParseFloat(strings.TrimSpace(row[i]), 64)
+		if err != nil {
+			return Bin{}, err
+		}
+		values[i] = value
+	}
+	return Bin{Lower: values[0], Upper: values[1], Weight: values[2]}, nil
+}
@@ -0,0 +1,124 @@
+package random
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func writeFile(t *testing.T, dir string, name string, content string) string {
+	path := filepath.Join(dir, name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	return path
+}
+
+func TestLoadDistributions(t *testing.T) {
+	require := require.New(t)
+	defer func(skill, ping DistributionSet) {
+		GSkillDistributions, GPingDistributions = skill, ping
+	}(GSkillDistributions, GPingDistributions)
+
+	dir := t.TempDir()
+	writeFile(t, dir, "skill.csv", "lower,upper,weight\n100,200,1\n200,300,0\n")
+	path := writeFile(t, dir, "distributions.yaml", `
+skill:
+  default: {type: empirical, file: skill.csv}
+  modes:
+    bank_it: {type: uniform, min: 1000, max: 1000}
+  regions:
+    us: {type: uniform, min: 2000, max: 2000}
+  mode_regions:
+    bank_it:
+      us: {type: uniform, min: 3000, max: 3000}
+ping:
+  default: {type: normal, mean: 50, stddev: 0, clamp: {lower: 0, upper: 40}}
+`)
+	require.NoError(LoadDistributions(path))
+
+	r := New(1)
+	for i := 0; i < 100; i++ {
+		skill := GSkillDistributions.Pick("quick_cash", "europe").Sample(r)
+		require.GreaterOrEqual(skill, 100.0)
+		require.Less(skill, 200.0)
+	}
+	require.Equal(1000.0, GSkillDistributions.Pick("bank_it", "europe").Sample(r))
+	require.Equal(2000.0, GSkillDistributions.Pick("quick_cash", "us").Sample(r))
+	require.Equal(3000.0, GSkillDistributions.Pick("bank_it", "us").Sample(r))
+	require.Equal(40.0, GPingDistributions.Pick("bank_it", "us").Sample(r))
+
+	// JSON files are read as well, sections that are left out keep the
+	// current default.
+	path = writeFile(t, dir, "distributions.json", `{"skill": {"default": {"type": "uniform", "min": 5, "max": 5}}}`)
+	require.NoError(LoadDistributions(path))
+	require.Equal(5.0, GSkillDistributions.Pick("bank_it", "us").Sample(r))
+	require.Equal(40.0, GPingDistributions.Pick("bank_it", "us").Sample(r))
+
+	require.Error(LoadDistributions(filepath.Join(dir, "missing.yaml")))
+	path = writeFile(t, dir, "invalid.yaml", "skill:\n  default: {type: triangle}\n")
+	require.ErrorContains(LoadDistributions(path), `unknown distribution type "triangle"`)
+	require.Equal(5.0, GSkillDistributions.Pick("bank_it", "us").Sample(r), "failed loads keep the distributions")
+}
+
+func TestReadHistogram(t *testing.T) {
+	require := require.New(t)
+	dir := t.TempDir()
+
+	bins, err := readHistogram(writeFile(t, dir, "header.csv", "lower,upper,weight\n0, 10, 2\n10,20,1\n"))
+	require.NoError(err)
+	require.Equal([]Bin{{Lower: 0, Upper: 10, Weight: 2}, {Lower: 10, Upper: 20, Weight: 1}}, bins)
+
+	bins, err = readHistogram(writeFile(t, dir, "plain.csv", "0,10,2\n"))
+	require.NoError(err)
+	require.Len(bins, 1)
+
+	_, err = readHistogram(writeFile(t, dir, "columns.csv", "0,10\n"))
+	require.Error(err)
+	_, err = readHistogram(writeFile(t, dir, "value.csv", "0,10,1\n10,x,1\n"))
+	require.ErrorContains(err, "line 2")
+
+	_, err = NewEmpirical([]Bin{{Lower: 10, Upper: 0, Weight: 1}})
+	require.Error(err)
+	_, err = NewEmpirical([]Bin{{Lower: 0, Upper: 10, Weight: 0}})
+	require.Error(err)
+}
+
+func TestDistributionSpecChecks(t *testing.T) {
+	for name, spec := range map[string]DistributionSpec{
+		"uniform bounds":   {Type: "uniform", Min: 2, Max: 1},
+		"normal stddev":    {Type: "normal", StdDev: -1},
+		"lognormal sigma":  {Type: "lognormal", Sigma: -1},
+		"bimodal missing":  {Type: "bimodal", First: &DistributionSpec{Type: "uniform"}},
+		"bimodal weight":   {Type: "bimodal", First: &DistributionSpec{Type: "uniform"}, Second: &DistributionSpec{Type: "uniform"}, Weight: 1.5},
+		"bimodal invalid":  {Type: "bimodal", First: &DistributionSpec{Type: "cube"}, Second: &DistributionSpec{Type: "uniform"}, Weight: 0.5},
+		"clamp bounds":     {Type: "uniform", Clamp: &Bin{Lower: 10, Upper: 0}},
+		"empirical empty":  {Type: "empirical"},
+		"missing type":     {},
+		"missing csv file": {Type: "empirical", File: "missing.csv"},
+	} {
+		_, err := spec.Build(t.TempDir())
+		require.Error(t, err, name)
+	}
+}
+
+func TestBimodalAndClamped(t *testing.T) {
+	require := require.New(t)
+	r := New(1)
+
+	d := Bimodal{First: Uniform{Min: 0, Max: 0}, Second: Uniform{Min: 1, Max: 1}, Weight: 0.25}
+	first := 0
+	for i := 0; i < 10000; i++ {
+		if d.Sample(r) == 0 {
+			first++
+		}
+	}
+	require.InDelta(2500, first, 200)
+
+	clamped := Clamped{Distribution: Normal{Mean: 0, StdDev: 100}, Min: -1, Max: 1}
+	for i := 0; i < 1000; i++ {
+		v := clamped.Sample(r)
+		require.GreaterOrEqual(v, -1.0)
+		require.LessOrEqual(v, 1.0)
+	}
+}
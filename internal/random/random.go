@@ -1,27 +1,28 @@
 package random
 
 import (
+	"math"
 	"math/rand"
 
 	utils "sim/internal"
 )
 
-func FindGameMode() string {
-	return utils.GameModes[rand.Intn(len(utils.GameModes))]
+func FindGameMode(r *rand.Rand) string {
+	return utils.GameModes[r.Intn(len(utils.GameModes))]
 }
 
-func FindTrustedState() string {
+func FindTrustedState(r *rand.Rand) string {
 	if utils.GSimulationMode == utils.All {
 		modes := []string{utils.GTrustedNameFalse, utils.GTrustedNameTrue}
-		return modes[rand.Intn(len(modes))]
+		return modes[r.Intn(len(modes))]
 	} else {
 		return utils.GTrustedNameTrue
 	}
 }
 
-func FindPassword() string {
+func FindPassword(r *rand.Rand) string {
 	if utils.GSimulationMode == utils.All {
-		randomSeed := rand.Float64()
+		randomSeed := r.Float64()
 		if randomSeed > 0.8 {
 			return utils.GPasswordName
 		}
@@ -29,16 +30,20 @@ func FindPassword() string {
 	return ""
 }
 
-func FindSkill() float64 {
+// FindSkill draws a skill from the distribution configured for the mode and
+// region the player is queueing for.
+func FindSkill(r *rand.Rand, mode string, region string) float64 {
 	if utils.GSimulationMode == utils.All || utils.GSimulationMode == utils.OnlySkill {
-		return rand.Float64() * 500
+		return GSkillDistributions.Pick(mode, region).Sample(r)
 	}
 	return 0
 }
 
-func FindRegionRandom(region string) float64 {
+// FindRegionRandom draws a ping towards the region from the distribution
+// configured for it.
+func FindRegionRandom(r *rand.Rand, mode string, region string) float64 {
 	if utils.GSimulationMode == utils.All {
-		return rand.Float64() * 500
+		return math.Max(0, GPingDistributions.Pick(mode, region).Sample(r))
 	}
 	return 0
 }
@@ -0,0 +1,57 @@
+package random
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// NewSeed returns the seed to run with, a zero seed picks one from the clock.
+func NewSeed(seed int64) int64 {
+	if seed != 0 {
+		return seed
+	}
+	return time.Now().UnixNano()
+}
+
+// New returns a random source seeded with the given seed. The source is not
+// safe for concurrent use.
+func New(seed int64) *rand.Rand {
+	return rand.New(rand.NewSource(seed))
+}
+
+// Derive returns a new source seeded from r. Handing every unit of work its
+// own derived source keeps results reproducible regardless of which goroutine
+// ends up running it.
+func Derive(r *rand.Rand) *rand.Rand {
+	return New(r.Int63())
+}
+
+// NewLocked returns a seeded random source that is safe for concurrent use.
+// Results are only reproducible if the callers draw in the same order.
+func NewLocked(seed int64) *rand.Rand {
+	return rand.New(&lockedSource{source: rand.NewSource(seed).(rand.Source64)})
+}
+
+type lockedSource struct {
+	mu     sync.Mutex
+	source rand.Source64
+}
+
+func (s *lockedSource) Int63() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.source.Int63()
+}
+
+func (s *lockedSource) Uint64() uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.source.Uint64()
+}
+
+func (s *lockedSource) Seed(seed int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.source.Seed(seed)
+}
@@ -0,0 +1,53 @@
+package scenario
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ProfileName encodes everything that tells the profiles of a scenario apart,
+// for example europe.bank_it.skill_0-500.trusted.beginner.
+func ProfileName(region string, mode string, skillMin float64, skillMax float64, trusted bool, beginner bool) string {
+	trustedName := "untrusted"
+	if trusted {
+		trustedName = "trusted"
+	}
+	beginnerName := "open"
+	if beginner {
+		beginnerName = "beginner"
+	}
+	skill := fmt.Sprintf("skill_%s-%s", strconv.FormatFloat(skillMin, 'f', -1, 64), strconv.FormatFloat(skillMax, 'f', -1, 64))
+	return strings.Join([]string{region, mode, skill, trustedName, beginnerName}, ".")
+}
+
+// PasswordProfileName is the name of the profile of a private lobby.
+func PasswordProfileName(password string) string {
+	return "password." + password
+}
+
+// Mode returns the configuration of the named game mode.
+func (c Config) Mode(name string) (ModeConfig, bool) {
+	for _, mode := range c.Modes {
+		if mode.Name == name {
+			return mode, true
+		}
+	}
+	return ModeConfig{}, false
+}
+
+// Bucket returns the boundaries of the skill bucket a skill falls into, skills
+// outside of the buckets count towards the closest one. The overlap is
+// ignored, so every skill belongs to exactly one bucket.
+func (m ModeConfig) Bucket(skill float64) (float64, float64) {
+	if len(m.SkillBuckets) < 2 {
+		return 0, 0
+	}
+	bucket := sort.SearchFloat64s(m.SkillBuckets, skill)
+	if bucket < len(m.SkillBuckets) && m.SkillBuckets[bucket] == skill {
+		bucket++
+	}
+	bucket = max(1, min(bucket, len(m.SkillBuckets)-1))
+	return m.SkillBuckets[bucket-1], m.SkillBuckets[bucket]
+}
@@ -0,0 +1,239 @@
+package scenario
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	utils "sim/internal"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Config is the file format of a scenario, see cmd/director/scenario.yaml. The
+// director generates its match profiles from it and the frontend reports queue
+// times per profile.
+type Config struct {
+	Regions   []string       `yaml:"regions"`
+	Modes     []ModeConfig   `yaml:"modes"`
+	Passwords PasswordConfig `yaml:"passwords"`
+}
+
+// ModeConfig describes the profiles generated for one game mode.
+type ModeConfig struct {
+	Name           string `yaml:"name"`
+	PlayersPerGame int    `yaml:"players_per_game"`
+	Teams          int    `yaml:"teams"`
+	// Boundaries of the skill buckets, every neighbouring pair is a bucket
+	// that overlaps its neighbours by half of BucketOverlap on each side.
+	SkillBuckets  []float64 `yaml:"skill_buckets"`
+	BucketOverlap float64   `yaml:"bucket_overlap"`
+	// Largest skill difference within a match, widened by SkillCurve.
+	SkillWindow int                `yaml:"skill_window"`
+	SkillCurve  []SkillCurveConfig `yaml:"skill_curve"`
+	// Split the queues by trusted and beginner players.
+	TrustedSplit  bool `yaml:"trusted_split"`
+	BeginnerSplit bool `yaml:"beginner_split"`
+	Backfill      bool `yaml:"backfill"`
+	// Smallest partial match that opens a backfill, zero leaves it to the
+	// match function, and how long tickets wait for a full match first.
+	BackfillMinPlayers int               `yaml:"backfill_min_players"`
+	BackfillMinWait    time.Duration     `yaml:"backfill_min_wait"`
+	Strategy           string            `yaml:"strategy"`
+	StrategyParams     map[string]string `yaml:"strategy_params"`
+	MaxPing            float64           `yaml:"max_ping"`
+	MaxPingSpread      float64           `yaml:"max_ping_spread"`
+}
+
+type SkillCurveConfig struct {
+	Wait               time.Duration `yaml:"wait"`
+	MaxSkillDifference float64       `yaml:"max_skill_difference"`
+}
+
+// PasswordConfig describes the private lobbies, one profile per password.
+type PasswordConfig struct {
+	Lobbies []string `yaml:"lobbies"`
+	Players int      `yaml:"players"`
+}
+
+// Default is used when the director and the frontend are started without a
+// scenario file.
+func Default() Config {
+	config := Config{
+		Regions: utils.GRegions,
+		Passwords: PasswordConfig{
+			Lobbies: []string{utils.GPasswordArg},
+			Players: 16,
+		},
+	}
+
+	for _, mode := range utils.GameModes {
+		modeConfig := ModeConfig{
+			Name:           mode,
+			PlayersPerGame: 16,
+			Teams:          4,
+			SkillBuckets:   []float64{0, 500, 1500},
+			BucketOverlap:  float64(utils.GMaxSkill),
+			SkillWindow:    50,
+			SkillCurve: []SkillCurveConfig{
+				{Wait: 30 * time.Second, MaxSkillDifference: 100},
+				{Wait: 2 * time.Minute, MaxSkillDifference: 250},
+			},
+			TrustedSplit:    true,
+			Backfill:        true,
+			BackfillMinWait: 30 * time.Second,
+			Strategy:        utils.StrategySkillWindow,
+			MaxPing:         200,
+			MaxPingSpread:   100,
+		}
+		// Ranked tournaments are worth the slower partition with the smallest skill spread.
+		if mode == "tournament_ranked" {
+			modeConfig.Strategy = utils.StrategyMinSpread
+		}
+		config.Modes = append(config.Modes, modeConfig)
+	}
+	return config
+}
+
+// Load reads and validates a YAML or JSON scenario file.
+func Load(path string) (Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Config{}, err
+	}
+
+	config, err := Parse(data)
+	if err != nil {
+		return Config{}, fmt.Errorf("invalid scenario %s, got %w", path, err)
+	}
+	return config, nil
+}
+
+// Parse decodes a YAML or JSON scenario, unknown fields are rejected so that
+// typos do not silently fall back to defaults.
+func Parse(data []byte) (Config, error) {
+	config := Config{}
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&config); err != nil {
+		return Config{}, err
+	}
+	return config, config.Validate()
+}
+
+// Validate returns every problem of the scenario at once.
+func (c Config) Validate() error {
+	errs := []error{}
+	fail := func(format string, args ...interface{}) {
+		errs = append(errs, fmt.Errorf(format, args...))
+	}
+
+	if len(c.Regions) == 0 {
+		fail("regions: at least one region is required")
+	}
+	checkNames("regions", c.Regions, fail)
+
+	if len(c.Modes) == 0 {
+		fail("modes: at least one mode is required")
+	}
+	names := []string{}
+	for i, mode := range c.Modes {
+		names = append(names, mode.Name)
+		mode.validate(fmt.Sprintf("modes[%d] (%s)", i, mode.Name), fail)
+	}
+	checkNames("modes", names, fail)
+
+	checkNames("passwords.lobbies", c.Passwords.Lobbies, fail)
+	if len(c.Passwords.Lobbies) > 0 && c.Passwords.Players <= 0 {
+		fail("passwords.players: must be positive, got %d", c.Passwords.Players)
+	}
+
+	return errors.Join(errs...)
+}
+
+func (m ModeConfig) validate(path string, fail func(format string, args ...interface{})) {
+	if m.PlayersPerGame <= 0 {
+		fail("%s.players_per_game: must be positive, got %d", path, m.PlayersPerGame)
+	}
+	if m.Teams < 0 {
+		fail("%s.teams: must not be negative, got %d", path, m.Teams)
+	} else if m.Teams > 1 && m.PlayersPerGame%m.Teams != 0 {
+		fail("%s.teams: %d players can not be split into %d equal teams", path, m.PlayersPerGame, m.Teams)
+	}
+
+	if len(m.SkillBuckets) < 2 {
+		fail("%s.skill_buckets: at least two boundaries are required, got %d", path, len(m.SkillBuckets))
+	}
+	for i := 1; i < len(m.SkillBuckets); i++ {
+		if m.SkillBuckets[i] <= m.SkillBuckets[i-1] {
+			fail("%s.skill_buckets: boundaries must be increasing, got %v after %v", path, m.SkillBuckets[i], m.SkillBuckets[i-1])
+		}
+	}
+	if m.BucketOverlap < 0 {
+		fail("%s.bucket_overlap: must not be negative, got %v", path, m.BucketOverlap)
+	}
+
+	if m.SkillWindow < 0 {
+		fail("%s.skill_window: must not be negative, got %d", path, m.SkillWindow)
+	}
+	for i, point := range m.SkillCurve {
+		if point.Wait <= 0 {
+			fail("%s.skill_curve[%d]: wait must be positive, got %s", path, i, point.Wait)
+		}
+		if point.MaxSkillDifference < 0 {
+			fail("%s.skill_curve[%d]: max_skill_difference must not be negative, got %v", path, i, point.MaxSkillDifference)
+		}
+		if i > 0 && point.Wait <= m.SkillCurve[i-1].Wait {
+			fail("%s.skill_curve[%d]: waits must be increasing, got %s after %s", path, i, point.Wait, m.SkillCurve[i-1].Wait)
+		}
+	}
+
+	if m.BackfillMinPlayers < 0 || m.BackfillMinPlayers > m.PlayersPerGame {
+		fail("%s.backfill_min_players: must be between 0 and %d, got %d", path, m.PlayersPerGame, m.BackfillMinPlayers)
+	}
+	if m.BackfillMinPlayers > 0 && !m.Backfill {
+		fail("%s.backfill_min_players: backfill is disabled", path)
+	}
+	if m.BackfillMinWait < 0 {
+		fail("%s.backfill_min_wait: must not be negative, got %s", path, m.BackfillMinWait)
+	}
+	if m.BackfillMinWait > 0 && !m.Backfill {
+		fail("%s.backfill_min_wait: backfill is disabled", path)
+	}
+
+	if err := utils.CheckStrategy(m.Strategy, m.StrategyParams); err != nil {
+		fail("%s.strategy: %s", path, err.Error())
+	}
+	if m.MaxPing < 0 {
+		fail("%s.max_ping: must not be negative, got %v", path, m.MaxPing)
+	}
+	if m.MaxPingSpread < 0 {
+		fail("%s.max_ping_spread: must not be negative, got %v", path, m.MaxPingSpread)
+	}
+}
+
+// checkNames reports empty names, names that can not be used as a tag and
+// duplicates.
+func checkNames(path string, names []string, fail func(format string, args ...interface{})) {
+	seen := map[string]bool{}
+	duplicates := []string{}
+	for i, name := range names {
+		switch {
+		case name == "":
+			fail("%s[%d]: name is required", path, i)
+		case strings.ContainsAny(name, " \t\n"):
+			fail("%s[%d]: name %q must not contain whitespace", path, i, name)
+		case seen[name]:
+			duplicates = append(duplicates, name)
+		}
+		seen[name] = true
+	}
+	if len(duplicates) > 0 {
+		sort.Strings(duplicates)
+		fail("%s: duplicate names %s", path, strings.Join(duplicates, ", "))
+	}
+}
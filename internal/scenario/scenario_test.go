@@ -0,0 +1,75 @@
+package scenario
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidate(t *testing.T) {
+	require.NoError(t, Default().Validate())
+
+	_, err := Parse([]byte(`
+regions: [europe, europe]
+modes:
+  - name: bank_it
+    players_per_game: 10
+    teams: 4
+    skill_buckets: [500, 0]
+    skill_curve:
+      - {wait: 1m, max_skill_difference: 100}
+      - {wait: 30s, max_skill_difference: 200}
+    backfill_min_wait: -1s
+    strategy: random
+  - name: snipe_it
+    players_per_game: 10
+    skill_buckets: [0, 500]
+    skill_curve:
+      - {wait: 0s, max_skill_difference: 100}
+passwords:
+  lobbies: [secret]
+`))
+	require.Error(t, err)
+	for _, message := range []string{
+		"regions: duplicate names europe",
+		"modes[0] (bank_it).teams: 10 players can not be split into 4 equal teams",
+		"modes[0] (bank_it).skill_buckets: boundaries must be increasing",
+		"modes[0] (bank_it).skill_curve[1]: waits must be increasing",
+		"modes[0] (bank_it).backfill_min_wait: must not be negative",
+		"modes[0] (bank_it).strategy: unknown strategy \"random\"",
+		"modes[1] (snipe_it).skill_curve[0]: wait must be positive, got 0s",
+		"passwords.players: must be positive",
+	} {
+		require.Contains(t, err.Error(), message)
+	}
+
+	_, err = Parse([]byte("regions: [europe]\nmodez: []\n"))
+	require.ErrorContains(t, err, "field modez not found")
+}
+
+func TestProfileName(t *testing.T) {
+	require.Equal(t, "europe.bank_it.skill_0-500.trusted.beginner", ProfileName("europe", "bank_it", 0, 500, true, true))
+	require.Equal(t, "us.quick_cash.skill_0.5-1500.untrusted.open", ProfileName("us", "quick_cash", 0.5, 1500, false, false))
+	require.Equal(t, "password.secret", PasswordProfileName("secret"))
+}
+
+func TestBucket(t *testing.T) {
+	mode, ok := Default().Mode("quick_cash")
+	require.True(t, ok)
+	_, ok = Default().Mode("unknown")
+	require.False(t, ok)
+
+	for _, tt := range []struct {
+		skill    float64
+		min, max float64
+	}{
+		{skill: 100, min: 0, max: 500},
+		{skill: 500, min: 500, max: 1500},
+		{skill: 4000, min: 500, max: 1500},
+		{skill: -10, min: 0, max: 500},
+	} {
+		skillMin, skillMax := mode.Bucket(tt.skill)
+		require.Equal(t, tt.min, skillMin, "skill %v", tt.skill)
+		require.Equal(t, tt.max, skillMax, "skill %v", tt.skill)
+	}
+}
@@ -0,0 +1,85 @@
+package utils
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// Strategies of the match function, profiles name theirs in ProfileSettings.
+const (
+	StrategyPools        = "pools"
+	StrategyFIFO         = "fifo"
+	StrategySkillWindow  = "skill_window"
+	StrategyMinSpread    = "min_spread"
+	StrategyLatencyFirst = "latency_first"
+)
+
+// PartitionParam selects how the skill_window strategy splits the skill sorted
+// tickets into matches, one of GPartitionModes.
+const (
+	PartitionParam     = "partition"
+	PartitionGreedy    = "greedy"
+	PartitionMinSpread = "min-spread"
+)
+
+var (
+	// GStrategyParams lists every strategy with the parameters it accepts, the
+	// director checks its profiles against it and the match function only
+	// registers strategies listed here.
+	GStrategyParams = map[string][]string{
+		StrategyPools:        nil,
+		StrategyFIFO:         nil,
+		StrategySkillWindow:  {PartitionParam},
+		StrategyMinSpread:    nil,
+		StrategyLatencyFirst: nil,
+	}
+	GPartitionModes = []string{PartitionGreedy, PartitionMinSpread}
+)
+
+// StrategyNames returns the strategies in alphabetical order.
+func StrategyNames() []string {
+	names := []string{}
+	for name := range GStrategyParams {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// CheckStrategy fails if the strategy or one of its parameters is unknown. An
+// empty name picks the default strategy of the match function, which checks
+// the parameters once it knows the strategy.
+func CheckStrategy(name string, params map[string]string) error {
+	if name == "" {
+		return nil
+	}
+	known, ok := GStrategyParams[name]
+	if !ok {
+		return fmt.Errorf("unknown strategy %q, expected one of %s", name, strings.Join(StrategyNames(), ", "))
+	}
+
+	names := []string{}
+	for param := range params {
+		names = append(names, param)
+	}
+	sort.Strings(names)
+	for _, param := range names {
+		if !slices.Contains(known, param) {
+			return fmt.Errorf("unknown strategy parameter %q", param)
+		}
+	}
+	if mode, ok := params[PartitionParam]; ok {
+		return CheckPartitionMode(mode)
+	}
+	return nil
+}
+
+// CheckPartitionMode fails if the skill_window strategy does not know the mode.
+func CheckPartitionMode(mode string) error {
+	if !slices.Contains(GPartitionModes, mode) {
+		return fmt.Errorf("unknown partition mode %q, expected %s", mode, strings.Join(GPartitionModes, " or "))
+	}
+	return nil
+}
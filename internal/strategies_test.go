@@ -0,0 +1,19 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckStrategy(t *testing.T) {
+	require := require.New(t)
+
+	require.NoError(CheckStrategy("", nil), "The match function picks the default")
+	require.NoError(CheckStrategy(StrategyFIFO, nil))
+	require.NoError(CheckStrategy(StrategySkillWindow, map[string]string{PartitionParam: PartitionMinSpread}))
+
+	require.ErrorContains(CheckStrategy("random", nil), "unknown strategy \"random\", expected one of fifo, latency_first, min_spread, pools, skill_window")
+	require.ErrorContains(CheckStrategy(StrategyFIFO, map[string]string{PartitionParam: PartitionGreedy}), "unknown strategy parameter \"partition\"")
+	require.ErrorContains(CheckStrategy(StrategySkillWindow, map[string]string{PartitionParam: "sideways"}), "unknown partition mode \"sideways\", expected greedy or min-spread")
+}
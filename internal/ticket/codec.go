@@ -0,0 +1,179 @@
+package ticket
+
+import (
+	"fmt"
+	"slices"
+
+	"sim/cmd/frontend/client"
+	utils "sim/internal"
+	simproto "sim/proto"
+
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"open-match.dev/open-match/pkg/pb"
+)
+
+// SchemaVersion is written to every ticket, tickets with another version are
+// rejected by DecodeTicket.
+const SchemaVersion = 1
+
+// MakeTicket encodes the matchmaking data into a Ticket that DecodeTicket can
+// read back. It fails if the data can not be represented, for example when no
+// region is left to queue for.
+func MakeTicket(clientData ClientMatchmakingData) (*pb.Ticket, error) {
+	if clientData.GameMode == "" {
+		return nil, fmt.Errorf("missing game mode")
+	}
+	for region := range clientData.RegionData.Pings {
+		if region == "" {
+			return nil, fmt.Errorf("empty region name in pings %v", clientData.RegionData.Pings)
+		}
+	}
+
+	ticket := &pb.Ticket{
+		SearchFields: &pb.SearchFields{
+			// Tags can support multiple values but for simplicity, the demo function
+			// assumes only single mode selection per Ticket.
+			Tags: []string{
+				clientData.GameMode,
+				clientData.Trusted,
+			},
+			DoubleArgs: map[string]float64{
+				utils.GSkillArg:     clientData.Skill,
+				utils.GPartySizeArg: float64(clientData.PartySize()),
+			},
+		},
+		Extensions: make(map[string]*anypb.Any),
+	}
+
+	if clientData.Password != "" {
+		ticket.SearchFields.Tags = append(ticket.SearchFields.Tags, clientData.Password)
+	}
+	if clientData.Beginner {
+		ticket.SearchFields.Tags = append(ticket.SearchFields.Tags, utils.GBeginnerName)
+	}
+
+	var desiredRegions []client.PingData
+	if clientData.RegionPolicy != nil {
+		desiredRegions = clientData.RegionPolicy.Select(clientData.RegionData.Pings)
+	} else {
+		desiredRegions = client.GetDesiredRegionsForMode(clientData.GameMode, clientData.RegionData.Pings)
+	}
+	if len(desiredRegions) == 0 {
+		return nil, fmt.Errorf("no desired regions for pings %v", clientData.RegionData.Pings)
+	}
+
+	for index := range desiredRegions {
+		ticket.SearchFields.Tags = append(ticket.SearchFields.Tags, desiredRegions[index].Region)
+	}
+	profile := &simproto.PlayerProfile{
+		SchemaVersion: SchemaVersion,
+		PlayerId:      clientData.PlayerID,
+		Latencies: &simproto.RegionLatencies{
+			Pings:      clientData.RegionData.Pings,
+			BestRegion: desiredRegions[0].Region,
+		},
+		SkillWindow:    clientData.SkillWindow,
+		ExpansionLevel: int32(clientData.ExpansionLevel),
+	}
+	if ids := clientData.PlayerIDs(); len(clientData.Members) > 0 && len(ids) > 0 && ids[0] != "" {
+		profile.PartyMembers = ids
+	}
+	if !clientData.OriginalCreateTime.IsZero() {
+		profile.OriginalCreateTime = timestamppb.New(clientData.OriginalCreateTime)
+	}
+	utils.SetMessage(ticket.Extensions, utils.TicketPlayerProfile, profile)
+
+	return ticket, nil
+}
+
+// DecodeTicket reads the matchmaking data back from a ticket created by
+// MakeTicket. The regions the ticket queued for are kept through a fixed
+// region policy. Party members only keep their player IDs, their skill and
+// pings are part of the aggregated values of the ticket.
+func DecodeTicket(t *pb.Ticket) (ClientMatchmakingData, error) {
+	returnData := ClientMatchmakingData{
+		RegionData: client.ClientRegionData{
+			Pings: make(map[string]float64),
+		},
+	}
+	if t.GetSearchFields() == nil {
+		return returnData, fmt.Errorf("ticket %s has no search fields", t.GetId())
+	}
+
+	profile, err := GetPlayerProfile(t)
+	if err != nil {
+		return returnData, err
+	}
+	for region, ping := range profile.GetLatencies().GetPings() {
+		returnData.RegionData.Pings[region] = ping
+	}
+
+	regions := []string{}
+	for _, tag := range t.SearchFields.Tags {
+		switch {
+		case slices.Contains(utils.GameModes, tag):
+			returnData.GameMode = tag
+		case tag == utils.GTrustedNameTrue || tag == utils.GTrustedNameFalse:
+			returnData.Trusted = tag
+		case tag == utils.GBeginnerName:
+			returnData.Beginner = true
+		case hasKey(returnData.RegionData.Pings, tag):
+			regions = append(regions, tag)
+		default:
+			if returnData.Password != "" {
+				return returnData, fmt.Errorf("ticket %s has unknown tag %s", t.GetId(), tag)
+			}
+			returnData.Password = tag
+		}
+	}
+	if returnData.GameMode == "" {
+		return returnData, fmt.Errorf("ticket %s has no game mode", t.GetId())
+	}
+	if len(regions) == 0 {
+		return returnData, fmt.Errorf("ticket %s has no regions", t.GetId())
+	}
+	returnData.RegionPolicy = client.FixedRegions{Regions: regions}
+
+	skill, ok := t.SearchFields.DoubleArgs[utils.GSkillArg]
+	if !ok {
+		return returnData, fmt.Errorf("ticket %s has no skill", t.GetId())
+	}
+	returnData.Skill = skill
+
+	returnData.PlayerID = profile.GetPlayerId()
+	if size := GetPartySizeFromTicket(t); size > 1 {
+		returnData.Members = make([]PartyMember, size)
+		for index, id := range profile.GetPartyMembers() {
+			if index < size {
+				returnData.Members[index].PlayerID = id
+			}
+		}
+	}
+
+	returnData.SkillWindow = profile.GetSkillWindow()
+	returnData.ExpansionLevel = int(profile.GetExpansionLevel())
+	if profile.GetOriginalCreateTime() != nil {
+		returnData.OriginalCreateTime = profile.GetOriginalCreateTime().AsTime()
+	}
+
+	return returnData, nil
+}
+
+// GetPlayerProfile reads the player profile of a ticket and checks its schema
+// version.
+func GetPlayerProfile(t *pb.Ticket) (*simproto.PlayerProfile, error) {
+	profile, err := utils.GetMessage(t.GetExtensions(), utils.TicketPlayerProfile)
+	if err != nil {
+		return nil, fmt.Errorf("ticket %s has no player profile, got %w", t.GetId(), err)
+	}
+	if profile.GetSchemaVersion() != SchemaVersion {
+		return nil, fmt.Errorf("ticket %s has unsupported schema version %d, expected %d", t.GetId(), profile.GetSchemaVersion(), SchemaVersion)
+	}
+	return profile, nil
+}
+
+func hasKey(m map[string]float64, key string) bool {
+	_, ok := m[key]
+	return ok
+}
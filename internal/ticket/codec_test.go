@@ -0,0 +1,94 @@
+package ticket
+
+import (
+	"testing"
+	"time"
+
+	"sim/cmd/frontend/client"
+	utils "sim/internal"
+	"sim/internal/random"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRoundTrip(t *testing.T) {
+	require := require.New(t)
+
+	clientData := ClientMatchmakingData{
+		PlayerID: "player-000001",
+		RegionData: client.ClientRegionData{
+			Pings: map[string]float64{"europe": 40, "us": 120, "asia": 400},
+		},
+		Trusted:            utils.GTrustedNameTrue,
+		Password:           utils.GPasswordName,
+		Skill:              725,
+		GameMode:           "tournament_ranked",
+		Beginner:           true,
+		SkillWindow:        150,
+		ExpansionLevel:     2,
+		OriginalCreateTime: time.Unix(1700000000, 0),
+	}
+
+	encoded, err := MakeTicket(clientData)
+	require.NoError(err)
+	require.Contains(encoded.SearchFields.Tags, utils.GBeginnerName, "Beginner is encoded")
+
+	decoded, err := DecodeTicket(encoded)
+	require.NoError(err)
+	require.Equal(clientData.PlayerID, decoded.PlayerID)
+	require.Equal(clientData.RegionData, decoded.RegionData)
+	require.Equal(clientData.Trusted, decoded.Trusted)
+	require.Equal(clientData.Password, decoded.Password)
+	require.Equal(clientData.Skill, decoded.Skill)
+	require.Equal(clientData.GameMode, decoded.GameMode)
+	require.True(decoded.Beginner)
+	require.Equal(clientData.SkillWindow, decoded.SkillWindow)
+	require.Equal(clientData.ExpansionLevel, decoded.ExpansionLevel)
+	require.True(clientData.OriginalCreateTime.Equal(decoded.OriginalCreateTime))
+
+	reencoded, err := MakeTicket(decoded)
+	require.NoError(err)
+	require.ElementsMatch(encoded.SearchFields.Tags, reencoded.SearchFields.Tags, "Regions survive the round trip")
+}
+
+func TestRoundTripParty(t *testing.T) {
+	require := require.New(t)
+
+	party, err := CreateRandomParty(random.New(1), 3, AggregateAverage)
+	require.NoError(err)
+	for index := range party.Members {
+		party.Members[index].PlayerID = []string{"a", "b", "c"}[index]
+	}
+
+	encoded, err := MakeTicket(party)
+	require.NoError(err)
+	decoded, err := DecodeTicket(encoded)
+	require.NoError(err)
+	require.Equal(3, decoded.PartySize())
+	require.Equal([]string{"a", "b", "c"}, decoded.PlayerIDs())
+}
+
+func TestEncodeErrors(t *testing.T) {
+	require := require.New(t)
+
+	_, err := MakeTicket(ClientMatchmakingData{GameMode: "bank_it"})
+	require.Error(err, "Tickets need at least one region")
+
+	_, err = MakeTicket(ClientMatchmakingData{
+		RegionData: client.ClientRegionData{Pings: map[string]float64{"europe": 10}},
+	})
+	require.Error(err, "Tickets need a game mode")
+
+	encoded, err := MakeTicket(ClientMatchmakingData{
+		RegionData: client.ClientRegionData{Pings: map[string]float64{"europe": 10}},
+		GameMode:   "bank_it",
+		Trusted:    utils.GTrustedNameFalse,
+	})
+	require.NoError(err)
+	profile, err := GetPlayerProfile(encoded)
+	require.NoError(err)
+	profile.SchemaVersion = SchemaVersion + 1
+	utils.SetMessage(encoded.Extensions, utils.TicketPlayerProfile, profile)
+	_, err = DecodeTicket(encoded)
+	require.Error(err, "Unknown schema versions are rejected")
+}
@@ -0,0 +1,142 @@
+package ticket
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+
+	"sim/cmd/frontend/client"
+)
+
+// PartyMember is a single player inside a party ticket.
+type PartyMember struct {
+	PlayerID string
+	Skill    float64
+	Pings    map[string]float64
+}
+
+// AggregationStrategy decides how the skill and pings of party members are
+// combined into the search fields of the ticket.
+type AggregationStrategy string
+
+const (
+	// AggregateMax uses the highest skill and the worst ping of the party.
+	AggregateMax AggregationStrategy = "max"
+	// AggregateAverage uses the mean skill and ping of the party.
+	AggregateAverage AggregationStrategy = "average"
+	// AggregateWeighted weighs every member by its own value, so the strongest
+	// player and the worst connection count more than in the plain average.
+	AggregateWeighted AggregationStrategy = "weighted"
+)
+
+func ParseAggregationStrategy(name string) (AggregationStrategy, error) {
+	switch strategy := AggregationStrategy(name); strategy {
+	case AggregateMax, AggregateAverage, AggregateWeighted:
+		return strategy, nil
+	}
+	return "", fmt.Errorf("unknown party aggregation strategy %q", name)
+}
+
+// PartySize returns the number of players the ticket represents.
+func (c ClientMatchmakingData) PartySize() int {
+	if len(c.Members) == 0 {
+		return 1
+	}
+	return len(c.Members)
+}
+
+// PlayerIDs returns the IDs of every player on the ticket.
+func (c ClientMatchmakingData) PlayerIDs() []string {
+	if len(c.Members) == 0 {
+		if c.PlayerID == "" {
+			return nil
+		}
+		return []string{c.PlayerID}
+	}
+	ids := []string{}
+	for _, member := range c.Members {
+		ids = append(ids, member.PlayerID)
+	}
+	return ids
+}
+
+// MakeParty turns the given members into party matchmaking data, the first
+// member is the leader whose preferences are kept.
+func MakeParty(leader ClientMatchmakingData, members []PartyMember, strategy AggregationStrategy) (ClientMatchmakingData, error) {
+	if len(members) == 0 {
+		return ClientMatchmakingData{}, fmt.Errorf("party without members")
+	}
+
+	returnData := leader
+	returnData.Members = members
+
+	skills := []float64{}
+	for _, member := range members {
+		skills = append(skills, member.Skill)
+	}
+	returnData.Skill = aggregate(skills, strategy)
+
+	returnData.RegionData = client.ClientRegionData{
+		Pings: make(map[string]float64),
+	}
+	for region := range members[0].Pings {
+		pings := []float64{}
+		for _, member := range members {
+			if ping, ok := member.Pings[region]; ok {
+				pings = append(pings, ping)
+			}
+		}
+		// A region is only usable if every member has a ping towards it.
+		if len(pings) == len(members) {
+			returnData.RegionData.Pings[region] = aggregate(pings, strategy)
+		}
+	}
+
+	return returnData, nil
+}
+
+// CreateRandomParty creates a party of the given size with random members.
+func CreateRandomParty(r *rand.Rand, size int, strategy AggregationStrategy) (ClientMatchmakingData, error) {
+	leader := CreateRandomMatchmakingData(r)
+	members := []PartyMember{}
+	for i := 0; i < size; i++ {
+		pings := createRandomPings(r, leader.GameMode)
+		member := PartyMember{
+			Skill: createRandomSkill(r, leader.GameMode, pings),
+			Pings: pings,
+		}
+		members = append(members, member)
+	}
+	return MakeParty(leader, members, strategy)
+}
+
+func aggregate(values []float64, strategy AggregationStrategy) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+
+	switch strategy {
+	case AggregateMax:
+		result := math.Inf(-1)
+		for _, v := range values {
+			result = math.Max(result, v)
+		}
+		return result
+	case AggregateWeighted:
+		sum, weightedSum := 0.0, 0.0
+		for _, v := range values {
+			sum += v
+			weightedSum += v * v
+		}
+		if sum == 0 {
+			return 0
+		}
+		return weightedSum / sum
+	}
+
+	sum := 0.0
+	for _, v := range values {
+		sum += v
+	}
+	return sum / float64(len(values))
+}
@@ -0,0 +1,34 @@
+package ticket
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMakeParty(t *testing.T) {
+	require := require.New(t)
+
+	leader := ClientMatchmakingData{GameMode: "bank_it", Skill: 999}
+	members := []PartyMember{
+		{PlayerID: "a", Skill: 100, Pings: map[string]float64{"europe": 20, "us": 150}},
+		{PlayerID: "b", Skill: 300, Pings: map[string]float64{"europe": 60}},
+	}
+
+	party, err := MakeParty(leader, members, AggregateMax)
+	require.NoError(err)
+	require.Equal("bank_it", party.GameMode)
+	require.Equal(300.0, party.Skill)
+	require.Equal(map[string]float64{"europe": 60}, party.RegionData.Pings, "Only regions every member reaches are kept")
+	require.Equal([]string{"a", "b"}, party.PlayerIDs())
+
+	party, err = MakeParty(leader, members, AggregateAverage)
+	require.NoError(err)
+	require.Equal(200.0, party.Skill)
+	require.Equal(40.0, party.RegionData.Pings["europe"])
+
+	_, err = MakeParty(leader, nil, AggregateMax)
+	require.Error(err)
+	_, err = MakeParty(leader, []PartyMember{}, AggregateMax)
+	require.Error(err)
+}
@@ -15,95 +15,142 @@
 package ticket
 
 import (
-	// Uncomment if following the tutorial
-	// "math/rand"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"time"
 
 	"sim/cmd/frontend/client"
 	utils "sim/internal"
+	"sim/internal/geo"
 	"sim/internal/random"
 
-	"google.golang.org/protobuf/types/known/anypb"
 	"open-match.dev/open-match/pkg/pb"
 )
 
 type ClientMatchmakingData struct {
+	PlayerID   string
 	RegionData client.ClientRegionData
 	Trusted    string
 	Password   string
 	Skill      float64
 	GameMode   string
 	Beginner   bool
+	// Members is only set for party tickets, Skill and RegionData then hold the
+	// aggregated values of the party.
+	Members []PartyMember
+	// The fields below are set when a waiting ticket is expanded. RegionPolicy
+	// overrides the policy of the game mode and SkillWindow is the skill
+	// difference the client accepts, zero leaves it up to the match profile.
+	RegionPolicy   client.RegionPolicy
+	SkillWindow    float64
+	ExpansionLevel int
+	// OriginalCreateTime is when the client first started queueing, it is kept
+	// when the ticket is re-created.
+	OriginalCreateTime time.Time
 }
 
-func CreateRandomMatchmakingData() ClientMatchmakingData {
+func CreateRandomMatchmakingData(r *rand.Rand) ClientMatchmakingData {
 	returnData := ClientMatchmakingData{
 		RegionData: client.ClientRegionData{
 			Pings: make(map[string]float64),
 		},
-		Trusted:  random.FindTrustedState(),
-		Password: random.FindPassword(),
-		Skill:    random.FindSkill(),
-		GameMode: random.FindGameMode(),
+		Trusted:  random.FindTrustedState(r),
+		Password: random.FindPassword(r),
+		GameMode: random.FindGameMode(r),
 	}
 
-	allRegions := utils.GRegions
-
-	for _, region := range allRegions {
-		returnData.RegionData.Pings[region] = random.FindRegionRandom(region)
-	}
+	returnData.RegionData.Pings = createRandomPings(r, returnData.GameMode)
+	returnData.Skill = createRandomSkill(r, returnData.GameMode, returnData.RegionData.Pings)
 
 	return returnData
 }
 
-// Ticket generates a Ticket with a mode search field that has one of the
-// randomly selected modes.
-func MakeTicket(clientData ClientMatchmakingData) *pb.Ticket {
-	ticket := &pb.Ticket{
-		SearchFields: &pb.SearchFields{
-			// Tags can support multiple values but for simplicity, the demo function
-			// assumes only single mode selection per Ticket.
-			Tags: []string{
-				clientData.GameMode,
-				clientData.Trusted,
-			},
-			DoubleArgs: map[string]float64{
-				utils.GSkillArg: clientData.Skill,
-			},
-		},
-		Extensions: make(map[string]*anypb.Any),
+// createRandomPings uses the geographic latency model when one is configured
+// and the per region ping distributions otherwise.
+func createRandomPings(r *rand.Rand, mode string) map[string]float64 {
+	if geo.GLatencyModel != nil && utils.GSimulationMode == utils.All {
+		return geo.GLatencyModel.Pings(r, geo.GLatencyModel.PlacePlayer(r))
 	}
 
-	if clientData.Password != "" {
-		ticket.SearchFields.Tags = append(ticket.SearchFields.Tags, clientData.Password)
+	pings := make(map[string]float64)
+	for _, region := range utils.GRegions {
+		pings[region] = random.FindRegionRandom(r, mode, region)
 	}
+	return pings
+}
 
-	desiredRegions := client.GetDesiredRegions(clientData.RegionData.Pings)
-	if len(desiredRegions) == 0 {
-		panic("expected regions to be filled in ")
+// createRandomSkill draws the skill for the region the player would most
+// likely play in.
+func createRandomSkill(r *rand.Rand, mode string, pings map[string]float64) float64 {
+	bestRegion := ""
+	if regions := client.GetDesiredRegions(pings); len(regions) > 0 {
+		bestRegion = regions[0].Region
 	}
+	return random.FindSkill(r, mode, bestRegion)
+}
+
+func GetSkillFromTicket(t *pb.Ticket) float64 {
+	return t.SearchFields.DoubleArgs[utils.GSkillArg]
+}
 
-	for index := range desiredRegions {
-		ticket.SearchFields.Tags = append(ticket.SearchFields.Tags, desiredRegions[index].Region)
+// GetPartySizeFromTicket returns the number of players on the ticket, tickets
+// without a party size count as a single player.
+func GetPartySizeFromTicket(t *pb.Ticket) int {
+	if size, ok := t.SearchFields.DoubleArgs[utils.GPartySizeArg]; ok && size >= 1 {
+		return int(size)
 	}
-	utils.AddExtensionString(ticket.Extensions, utils.GBestRegionKey, desiredRegions[0].Region)
-	for region, v := range clientData.RegionData.Pings {
-		utils.AddExtensionFloat64(ticket.Extensions, region, float64(v))
+	return 1
+}
+
+// GetWaitTimeFromTicket returns how long the client behind the ticket has been
+// queueing, including the time spent on tickets it replaced.
+func GetWaitTimeFromTicket(t *pb.Ticket, now time.Time) (time.Duration, error) {
+	profile, err := GetPlayerProfile(t)
+	if err != nil {
+		return 0, err
 	}
-	if len(desiredRegions) > 0 {
+	switch {
+	case profile.GetOriginalCreateTime() != nil:
+		return now.Sub(profile.GetOriginalCreateTime().AsTime()), nil
+	case t.GetCreateTime() != nil:
+		return now.Sub(t.GetCreateTime().AsTime()), nil
 	}
+	return 0, nil
+}
 
-	return ticket
+// GetSkillWindowFromTicket returns the skill difference the client accepts,
+// or fallback if the ticket does not widen it.
+func GetSkillWindowFromTicket(t *pb.Ticket, fallback float64) (float64, error) {
+	profile, err := GetPlayerProfile(t)
+	if err != nil {
+		return fallback, err
+	}
+	return math.Max(profile.GetSkillWindow(), fallback), nil
 }
 
-func GetSkillFromTicket(t *pb.Ticket) float64 {
-	return t.SearchFields.DoubleArgs[utils.GSkillArg]
+// ErrNoPing is returned for tickets without a ping towards a region.
+var ErrNoPing = errors.New("no ping for region")
+
+// GetLatencyFromTicket returns the ping of the ticket towards the region.
+func GetLatencyFromTicket(t *pb.Ticket, region string) (float64, error) {
+	profile, err := GetPlayerProfile(t)
+	if err != nil {
+		return 0, err
+	}
+	regionPing, ok := profile.GetLatencies().GetPings()[region]
+	if !ok {
+		return 0, fmt.Errorf("ticket %s, got %w %s", t.GetId(), ErrNoPing, region)
+	}
+	return regionPing, nil
 }
 
-func GetLatencyFromTicket(t *pb.Ticket, region string, bestRegionMaxPing int) float64 {
-	regionPing := utils.GetExtensionFloat64(t.Extensions, region)
-	bestRegion := utils.GetExtensionString(t.Extensions, utils.GBestRegionKey)
-	if bestRegion == region && regionPing > float64(bestRegionMaxPing) {
-		return float64(bestRegionMaxPing)
+// GetBestRegionFromTicket returns the region the client prefers.
+func GetBestRegionFromTicket(t *pb.Ticket) (string, error) {
+	profile, err := GetPlayerProfile(t)
+	if err != nil {
+		return "", err
 	}
-	return regionPing
+	return profile.GetLatencies().GetBestRegion(), nil
 }
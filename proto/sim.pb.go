@@ -1,6 +1,6 @@
 // Code generated by protoc-gen-go. DO NOT EDIT.
 // versions:
-// 	protoc-gen-go v1.28.1
+// 	protoc-gen-go v1.31.0
 // 	protoc        v3.12.4
 // source: proto/sim.proto
 
@@ -9,6 +9,8 @@ package simproto
 import (
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	durationpb "google.golang.org/protobuf/types/known/durationpb"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
 	reflect "reflect"
 	sync "sync"
 )
@@ -20,18 +22,20 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
-// A DefaultEvaluationCriteria is used for a match's evaluation_input when using
-// the default evaluator.
-type DefaultEvaluationString struct {
+// RegionLatencies holds the ping of a client towards every region it measured.
+type RegionLatencies struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Value string `protobuf:"bytes,1,opt,name=Value,proto3" json:"Value,omitempty"`
+	// Ping in milliseconds keyed by region name.
+	Pings map[string]float64 `protobuf:"bytes,1,rep,name=pings,proto3" json:"pings,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"fixed64,2,opt,name=value,proto3"`
+	// The region the client prefers, the first of its desired regions.
+	BestRegion string `protobuf:"bytes,2,opt,name=best_region,json=bestRegion,proto3" json:"best_region,omitempty"`
 }
 
-func (x *DefaultEvaluationString) Reset() {
-	*x = DefaultEvaluationString{}
+func (x *RegionLatencies) Reset() {
+	*x = RegionLatencies{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_proto_sim_proto_msgTypes[0]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -39,13 +43,13 @@ func (x *DefaultEvaluationString) Reset() {
 	}
 }
 
-func (x *DefaultEvaluationString) String() string {
+func (x *RegionLatencies) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*DefaultEvaluationString) ProtoMessage() {}
+func (*RegionLatencies) ProtoMessage() {}
 
-func (x *DefaultEvaluationString) ProtoReflect() protoreflect.Message {
+func (x *RegionLatencies) ProtoReflect() protoreflect.Message {
 	mi := &file_proto_sim_proto_msgTypes[0]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -57,28 +61,741 @@ func (x *DefaultEvaluationString) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use DefaultEvaluationString.ProtoReflect.Descriptor instead.
-func (*DefaultEvaluationString) Descriptor() ([]byte, []int) {
+// Deprecated: Use RegionLatencies.ProtoReflect.Descriptor instead.
+func (*RegionLatencies) Descriptor() ([]byte, []int) {
 	return file_proto_sim_proto_rawDescGZIP(), []int{0}
 }
 
-func (x *DefaultEvaluationString) GetValue() string {
+func (x *RegionLatencies) GetPings() map[string]float64 {
 	if x != nil {
-		return x.Value
+		return x.Pings
+	}
+	return nil
+}
+
+func (x *RegionLatencies) GetBestRegion() string {
+	if x != nil {
+		return x.BestRegion
 	}
 	return ""
 }
 
+// PlayerProfile is the matchmaking data of a ticket that is not searchable.
+type PlayerProfile struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	SchemaVersion int32  `protobuf:"varint,1,opt,name=schema_version,json=schemaVersion,proto3" json:"schema_version,omitempty"`
+	PlayerId      string `protobuf:"bytes,2,opt,name=player_id,json=playerId,proto3" json:"player_id,omitempty"`
+	// Player IDs of the party members, empty for single players.
+	PartyMembers []string         `protobuf:"bytes,3,rep,name=party_members,json=partyMembers,proto3" json:"party_members,omitempty"`
+	Latencies    *RegionLatencies `protobuf:"bytes,4,opt,name=latencies,proto3" json:"latencies,omitempty"`
+	// Skill difference the client accepts, zero leaves it up to the profile.
+	SkillWindow    float64 `protobuf:"fixed64,5,opt,name=skill_window,json=skillWindow,proto3" json:"skill_window,omitempty"`
+	ExpansionLevel int32   `protobuf:"varint,6,opt,name=expansion_level,json=expansionLevel,proto3" json:"expansion_level,omitempty"`
+	// When the client started queueing, kept across re-created tickets.
+	OriginalCreateTime *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=original_create_time,json=originalCreateTime,proto3" json:"original_create_time,omitempty"`
+}
+
+func (x *PlayerProfile) Reset() {
+	*x = PlayerProfile{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_sim_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *PlayerProfile) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PlayerProfile) ProtoMessage() {}
+
+func (x *PlayerProfile) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_sim_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PlayerProfile.ProtoReflect.Descriptor instead.
+func (*PlayerProfile) Descriptor() ([]byte, []int) {
+	return file_proto_sim_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *PlayerProfile) GetSchemaVersion() int32 {
+	if x != nil {
+		return x.SchemaVersion
+	}
+	return 0
+}
+
+func (x *PlayerProfile) GetPlayerId() string {
+	if x != nil {
+		return x.PlayerId
+	}
+	return ""
+}
+
+func (x *PlayerProfile) GetPartyMembers() []string {
+	if x != nil {
+		return x.PartyMembers
+	}
+	return nil
+}
+
+func (x *PlayerProfile) GetLatencies() *RegionLatencies {
+	if x != nil {
+		return x.Latencies
+	}
+	return nil
+}
+
+func (x *PlayerProfile) GetSkillWindow() float64 {
+	if x != nil {
+		return x.SkillWindow
+	}
+	return 0
+}
+
+func (x *PlayerProfile) GetExpansionLevel() int32 {
+	if x != nil {
+		return x.ExpansionLevel
+	}
+	return 0
+}
+
+func (x *PlayerProfile) GetOriginalCreateTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.OriginalCreateTime
+	}
+	return nil
+}
+
+// ProfileSettings configures the match function for a match profile.
+type ProfileSettings struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Region the matches are played in, empty for profiles of any region.
+	Region     string `protobuf:"bytes,1,opt,name=region,proto3" json:"region,omitempty"`
+	MaxPlayers int32  `protobuf:"varint,2,opt,name=max_players,json=maxPlayers,proto3" json:"max_players,omitempty"`
+	// Largest skill difference within a match, zero does not limit it.
+	MaxSkillDifference float64 `protobuf:"fixed64,3,opt,name=max_skill_difference,json=maxSkillDifference,proto3" json:"max_skill_difference,omitempty"`
+	// Number of teams the players of a match are split into, zero or one plays
+	// without teams.
+	NumTeams int32 `protobuf:"varint,4,opt,name=num_teams,json=numTeams,proto3" json:"num_teams,omitempty"`
+	// Name of the matchmaking strategy of the profile and its parameters, an
+	// empty name uses the default strategy of the match function.
+	Strategy       string            `protobuf:"bytes,5,opt,name=strategy,proto3" json:"strategy,omitempty"`
+	StrategyParams map[string]string `protobuf:"bytes,6,rep,name=strategy_params,json=strategyParams,proto3" json:"strategy_params,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	// Widens the skill difference a ticket accepts the longer it waits, the
+	// points have to be ordered by wait time.
+	SkillCurve []*SkillWindowPoint `protobuf:"bytes,7,rep,name=skill_curve,json=skillCurve,proto3" json:"skill_curve,omitempty"`
+	// Highest ping in milliseconds towards the region a ticket may have, tickets
+	// whose best region is the profile region are always accepted. Zero does not
+	// limit it.
+	MaxPing float64 `protobuf:"fixed64,8,opt,name=max_ping,json=maxPing,proto3" json:"max_ping,omitempty"`
+	// Largest ping difference between the tickets of a match, zero does not
+	// limit it.
+	MaxPingSpread float64 `protobuf:"fixed64,9,opt,name=max_ping_spread,json=maxPingSpread,proto3" json:"max_ping_spread,omitempty"`
+	// Whether matches that can not be filled start with a backfill, and the
+	// fewest players such a match starts with. Zero starts with half the players.
+	Backfill           bool  `protobuf:"varint,10,opt,name=backfill,proto3" json:"backfill,omitempty"`
+	BackfillMinPlayers int32 `protobuf:"varint,11,opt,name=backfill_min_players,json=backfillMinPlayers,proto3" json:"backfill_min_players,omitempty"`
+	// How long a ticket waits for a full match before it may start one with a
+	// backfill, zero starts them right away.
+	BackfillMinWait *durationpb.Duration `protobuf:"bytes,12,opt,name=backfill_min_wait,json=backfillMinWait,proto3" json:"backfill_min_wait,omitempty"`
+}
+
+func (x *ProfileSettings) Reset() {
+	*x = ProfileSettings{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_sim_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ProfileSettings) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ProfileSettings) ProtoMessage() {}
+
+func (x *ProfileSettings) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_sim_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ProfileSettings.ProtoReflect.Descriptor instead.
+func (*ProfileSettings) Descriptor() ([]byte, []int) {
+	return file_proto_sim_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *ProfileSettings) GetRegion() string {
+	if x != nil {
+		return x.Region
+	}
+	return ""
+}
+
+func (x *ProfileSettings) GetMaxPlayers() int32 {
+	if x != nil {
+		return x.MaxPlayers
+	}
+	return 0
+}
+
+func (x *ProfileSettings) GetMaxSkillDifference() float64 {
+	if x != nil {
+		return x.MaxSkillDifference
+	}
+	return 0
+}
+
+func (x *ProfileSettings) GetNumTeams() int32 {
+	if x != nil {
+		return x.NumTeams
+	}
+	return 0
+}
+
+func (x *ProfileSettings) GetStrategy() string {
+	if x != nil {
+		return x.Strategy
+	}
+	return ""
+}
+
+func (x *ProfileSettings) GetStrategyParams() map[string]string {
+	if x != nil {
+		return x.StrategyParams
+	}
+	return nil
+}
+
+func (x *ProfileSettings) GetSkillCurve() []*SkillWindowPoint {
+	if x != nil {
+		return x.SkillCurve
+	}
+	return nil
+}
+
+func (x *ProfileSettings) GetMaxPing() float64 {
+	if x != nil {
+		return x.MaxPing
+	}
+	return 0
+}
+
+func (x *ProfileSettings) GetMaxPingSpread() float64 {
+	if x != nil {
+		return x.MaxPingSpread
+	}
+	return 0
+}
+
+func (x *ProfileSettings) GetBackfill() bool {
+	if x != nil {
+		return x.Backfill
+	}
+	return false
+}
+
+func (x *ProfileSettings) GetBackfillMinPlayers() int32 {
+	if x != nil {
+		return x.BackfillMinPlayers
+	}
+	return 0
+}
+
+func (x *ProfileSettings) GetBackfillMinWait() *durationpb.Duration {
+	if x != nil {
+		return x.BackfillMinWait
+	}
+	return nil
+}
+
+// SkillWindowPoint is a point of the skill window curve of a profile. The
+// window grows linearly from max_skill_difference at no wait through the points
+// and stays at the last one.
+type SkillWindowPoint struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Wait               *durationpb.Duration `protobuf:"bytes,1,opt,name=wait,proto3" json:"wait,omitempty"`
+	MaxSkillDifference float64              `protobuf:"fixed64,2,opt,name=max_skill_difference,json=maxSkillDifference,proto3" json:"max_skill_difference,omitempty"`
+}
+
+func (x *SkillWindowPoint) Reset() {
+	*x = SkillWindowPoint{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_sim_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SkillWindowPoint) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SkillWindowPoint) ProtoMessage() {}
+
+func (x *SkillWindowPoint) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_sim_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SkillWindowPoint.ProtoReflect.Descriptor instead.
+func (*SkillWindowPoint) Descriptor() ([]byte, []int) {
+	return file_proto_sim_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *SkillWindowPoint) GetWait() *durationpb.Duration {
+	if x != nil {
+		return x.Wait
+	}
+	return nil
+}
+
+func (x *SkillWindowPoint) GetMaxSkillDifference() float64 {
+	if x != nil {
+		return x.MaxSkillDifference
+	}
+	return 0
+}
+
+// MatchQuality describes a match proposal.
+type MatchQuality struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	NumTickets int32 `protobuf:"varint,1,opt,name=num_tickets,json=numTickets,proto3" json:"num_tickets,omitempty"`
+	NumPlayers int32 `protobuf:"varint,2,opt,name=num_players,json=numPlayers,proto3" json:"num_players,omitempty"`
+	// Index of the match within the proposals of the profile.
+	Index int32 `protobuf:"varint,3,opt,name=index,proto3" json:"index,omitempty"`
+	// Longest time any ticket of the match has been queueing.
+	MaxWait *durationpb.Duration `protobuf:"bytes,4,opt,name=max_wait,json=maxWait,proto3" json:"max_wait,omitempty"`
+	// Negative sum of the squared differences to the average skill and latency,
+	// higher is better.
+	SkillQuality   float64 `protobuf:"fixed64,5,opt,name=skill_quality,json=skillQuality,proto3" json:"skill_quality,omitempty"`
+	LatencyQuality float64 `protobuf:"fixed64,6,opt,name=latency_quality,json=latencyQuality,proto3" json:"latency_quality,omitempty"`
+	// Weighted sum of the components above, also used as the evaluation input.
+	Score float64 `protobuf:"fixed64,7,opt,name=score,proto3" json:"score,omitempty"`
+}
+
+func (x *MatchQuality) Reset() {
+	*x = MatchQuality{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_sim_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *MatchQuality) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MatchQuality) ProtoMessage() {}
+
+func (x *MatchQuality) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_sim_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MatchQuality.ProtoReflect.Descriptor instead.
+func (*MatchQuality) Descriptor() ([]byte, []int) {
+	return file_proto_sim_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *MatchQuality) GetNumTickets() int32 {
+	if x != nil {
+		return x.NumTickets
+	}
+	return 0
+}
+
+func (x *MatchQuality) GetNumPlayers() int32 {
+	if x != nil {
+		return x.NumPlayers
+	}
+	return 0
+}
+
+func (x *MatchQuality) GetIndex() int32 {
+	if x != nil {
+		return x.Index
+	}
+	return 0
+}
+
+func (x *MatchQuality) GetMaxWait() *durationpb.Duration {
+	if x != nil {
+		return x.MaxWait
+	}
+	return nil
+}
+
+func (x *MatchQuality) GetSkillQuality() float64 {
+	if x != nil {
+		return x.SkillQuality
+	}
+	return 0
+}
+
+func (x *MatchQuality) GetLatencyQuality() float64 {
+	if x != nil {
+		return x.LatencyQuality
+	}
+	return 0
+}
+
+func (x *MatchQuality) GetScore() float64 {
+	if x != nil {
+		return x.Score
+	}
+	return 0
+}
+
+// Team is one side of a match, parties are never split across teams.
+type Team struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Index     int32    `protobuf:"varint,1,opt,name=index,proto3" json:"index,omitempty"`
+	TicketIds []string `protobuf:"bytes,2,rep,name=ticket_ids,json=ticketIds,proto3" json:"ticket_ids,omitempty"`
+	Players   int32    `protobuf:"varint,3,opt,name=players,proto3" json:"players,omitempty"`
+	// Sum of the skill of every player on the team.
+	Skill float64 `protobuf:"fixed64,4,opt,name=skill,proto3" json:"skill,omitempty"`
+}
+
+func (x *Team) Reset() {
+	*x = Team{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_sim_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Team) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Team) ProtoMessage() {}
+
+func (x *Team) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_sim_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Team.ProtoReflect.Descriptor instead.
+func (*Team) Descriptor() ([]byte, []int) {
+	return file_proto_sim_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *Team) GetIndex() int32 {
+	if x != nil {
+		return x.Index
+	}
+	return 0
+}
+
+func (x *Team) GetTicketIds() []string {
+	if x != nil {
+		return x.TicketIds
+	}
+	return nil
+}
+
+func (x *Team) GetPlayers() int32 {
+	if x != nil {
+		return x.Players
+	}
+	return 0
+}
+
+func (x *Team) GetSkill() float64 {
+	if x != nil {
+		return x.Skill
+	}
+	return 0
+}
+
+// TeamLayout is the team assignment of a match.
+type TeamLayout struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Teams []*Team `protobuf:"bytes,1,rep,name=teams,proto3" json:"teams,omitempty"`
+}
+
+func (x *TeamLayout) Reset() {
+	*x = TeamLayout{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_sim_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *TeamLayout) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TeamLayout) ProtoMessage() {}
+
+func (x *TeamLayout) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_sim_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TeamLayout.ProtoReflect.Descriptor instead.
+func (*TeamLayout) Descriptor() ([]byte, []int) {
+	return file_proto_sim_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *TeamLayout) GetTeams() []*Team {
+	if x != nil {
+		return x.Teams
+	}
+	return nil
+}
+
+// BackfillState describes the game server behind a backfill.
+type BackfillState struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	MaxPlayers int32 `protobuf:"varint,1,opt,name=max_players,json=maxPlayers,proto3" json:"max_players,omitempty"`
+	OpenSlots  int32 `protobuf:"varint,2,opt,name=open_slots,json=openSlots,proto3" json:"open_slots,omitempty"`
+	// Average skill of the players in the game.
+	Skill float64 `protobuf:"fixed64,3,opt,name=skill,proto3" json:"skill,omitempty"`
+}
+
+func (x *BackfillState) Reset() {
+	*x = BackfillState{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_sim_proto_msgTypes[7]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *BackfillState) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BackfillState) ProtoMessage() {}
+
+func (x *BackfillState) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_sim_proto_msgTypes[7]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BackfillState.ProtoReflect.Descriptor instead.
+func (*BackfillState) Descriptor() ([]byte, []int) {
+	return file_proto_sim_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *BackfillState) GetMaxPlayers() int32 {
+	if x != nil {
+		return x.MaxPlayers
+	}
+	return 0
+}
+
+func (x *BackfillState) GetOpenSlots() int32 {
+	if x != nil {
+		return x.OpenSlots
+	}
+	return 0
+}
+
+func (x *BackfillState) GetSkill() float64 {
+	if x != nil {
+		return x.Skill
+	}
+	return 0
+}
+
 var File_proto_sim_proto protoreflect.FileDescriptor
 
 var file_proto_sim_proto_rawDesc = []byte{
 	0x0a, 0x0f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2f, 0x73, 0x69, 0x6d, 0x2e, 0x70, 0x72, 0x6f, 0x74,
-	0x6f, 0x12, 0x03, 0x73, 0x69, 0x6d, 0x22, 0x2f, 0x0a, 0x17, 0x44, 0x65, 0x66, 0x61, 0x75, 0x6c,
-	0x74, 0x45, 0x76, 0x61, 0x6c, 0x75, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x53, 0x74, 0x72, 0x69, 0x6e,
-	0x67, 0x12, 0x14, 0x0a, 0x05, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
-	0x52, 0x05, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x42, 0x10, 0x5a, 0x0e, 0x70, 0x72, 0x6f, 0x74, 0x6f,
-	0x2f, 0x73, 0x69, 0x6d, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f,
-	0x33,
+	0x6f, 0x12, 0x03, 0x73, 0x69, 0x6d, 0x1a, 0x1e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2f, 0x64, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e,
+	0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x1f, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2f, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d,
+	0x70, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0xa3, 0x01, 0x0a, 0x0f, 0x52, 0x65, 0x67, 0x69,
+	0x6f, 0x6e, 0x4c, 0x61, 0x74, 0x65, 0x6e, 0x63, 0x69, 0x65, 0x73, 0x12, 0x35, 0x0a, 0x05, 0x70,
+	0x69, 0x6e, 0x67, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1f, 0x2e, 0x73, 0x69, 0x6d,
+	0x2e, 0x52, 0x65, 0x67, 0x69, 0x6f, 0x6e, 0x4c, 0x61, 0x74, 0x65, 0x6e, 0x63, 0x69, 0x65, 0x73,
+	0x2e, 0x50, 0x69, 0x6e, 0x67, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x05, 0x70, 0x69, 0x6e,
+	0x67, 0x73, 0x12, 0x1f, 0x0a, 0x0b, 0x62, 0x65, 0x73, 0x74, 0x5f, 0x72, 0x65, 0x67, 0x69, 0x6f,
+	0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x62, 0x65, 0x73, 0x74, 0x52, 0x65, 0x67,
+	0x69, 0x6f, 0x6e, 0x1a, 0x38, 0x0a, 0x0a, 0x50, 0x69, 0x6e, 0x67, 0x73, 0x45, 0x6e, 0x74, 0x72,
+	0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03,
+	0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x01, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x22, 0xc6, 0x02,
+	0x0a, 0x0d, 0x50, 0x6c, 0x61, 0x79, 0x65, 0x72, 0x50, 0x72, 0x6f, 0x66, 0x69, 0x6c, 0x65, 0x12,
+	0x25, 0x0a, 0x0e, 0x73, 0x63, 0x68, 0x65, 0x6d, 0x61, 0x5f, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f,
+	0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0d, 0x73, 0x63, 0x68, 0x65, 0x6d, 0x61, 0x56,
+	0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x1b, 0x0a, 0x09, 0x70, 0x6c, 0x61, 0x79, 0x65, 0x72,
+	0x5f, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x70, 0x6c, 0x61, 0x79, 0x65,
+	0x72, 0x49, 0x64, 0x12, 0x23, 0x0a, 0x0d, 0x70, 0x61, 0x72, 0x74, 0x79, 0x5f, 0x6d, 0x65, 0x6d,
+	0x62, 0x65, 0x72, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x09, 0x52, 0x0c, 0x70, 0x61, 0x72, 0x74,
+	0x79, 0x4d, 0x65, 0x6d, 0x62, 0x65, 0x72, 0x73, 0x12, 0x32, 0x0a, 0x09, 0x6c, 0x61, 0x74, 0x65,
+	0x6e, 0x63, 0x69, 0x65, 0x73, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x14, 0x2e, 0x73, 0x69,
+	0x6d, 0x2e, 0x52, 0x65, 0x67, 0x69, 0x6f, 0x6e, 0x4c, 0x61, 0x74, 0x65, 0x6e, 0x63, 0x69, 0x65,
+	0x73, 0x52, 0x09, 0x6c, 0x61, 0x74, 0x65, 0x6e, 0x63, 0x69, 0x65, 0x73, 0x12, 0x21, 0x0a, 0x0c,
+	0x73, 0x6b, 0x69, 0x6c, 0x6c, 0x5f, 0x77, 0x69, 0x6e, 0x64, 0x6f, 0x77, 0x18, 0x05, 0x20, 0x01,
+	0x28, 0x01, 0x52, 0x0b, 0x73, 0x6b, 0x69, 0x6c, 0x6c, 0x57, 0x69, 0x6e, 0x64, 0x6f, 0x77, 0x12,
+	0x27, 0x0a, 0x0f, 0x65, 0x78, 0x70, 0x61, 0x6e, 0x73, 0x69, 0x6f, 0x6e, 0x5f, 0x6c, 0x65, 0x76,
+	0x65, 0x6c, 0x18, 0x06, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0e, 0x65, 0x78, 0x70, 0x61, 0x6e, 0x73,
+	0x69, 0x6f, 0x6e, 0x4c, 0x65, 0x76, 0x65, 0x6c, 0x12, 0x4c, 0x0a, 0x14, 0x6f, 0x72, 0x69, 0x67,
+	0x69, 0x6e, 0x61, 0x6c, 0x5f, 0x63, 0x72, 0x65, 0x61, 0x74, 0x65, 0x5f, 0x74, 0x69, 0x6d, 0x65,
+	0x18, 0x07, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61,
+	0x6d, 0x70, 0x52, 0x12, 0x6f, 0x72, 0x69, 0x67, 0x69, 0x6e, 0x61, 0x6c, 0x43, 0x72, 0x65, 0x61,
+	0x74, 0x65, 0x54, 0x69, 0x6d, 0x65, 0x22, 0xdb, 0x04, 0x0a, 0x0f, 0x50, 0x72, 0x6f, 0x66, 0x69,
+	0x6c, 0x65, 0x53, 0x65, 0x74, 0x74, 0x69, 0x6e, 0x67, 0x73, 0x12, 0x16, 0x0a, 0x06, 0x72, 0x65,
+	0x67, 0x69, 0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x72, 0x65, 0x67, 0x69,
+	0x6f, 0x6e, 0x12, 0x1f, 0x0a, 0x0b, 0x6d, 0x61, 0x78, 0x5f, 0x70, 0x6c, 0x61, 0x79, 0x65, 0x72,
+	0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0a, 0x6d, 0x61, 0x78, 0x50, 0x6c, 0x61, 0x79,
+	0x65, 0x72, 0x73, 0x12, 0x30, 0x0a, 0x14, 0x6d, 0x61, 0x78, 0x5f, 0x73, 0x6b, 0x69, 0x6c, 0x6c,
+	0x5f, 0x64, 0x69, 0x66, 0x66, 0x65, 0x72, 0x65, 0x6e, 0x63, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28,
+	0x01, 0x52, 0x12, 0x6d, 0x61, 0x78, 0x53, 0x6b, 0x69, 0x6c, 0x6c, 0x44, 0x69, 0x66, 0x66, 0x65,
+	0x72, 0x65, 0x6e, 0x63, 0x65, 0x12, 0x1b, 0x0a, 0x09, 0x6e, 0x75, 0x6d, 0x5f, 0x74, 0x65, 0x61,
+	0x6d, 0x73, 0x18, 0x04, 0x20, 0x01, 0x28, 0x05, 0x52, 0x08, 0x6e, 0x75, 0x6d, 0x54, 0x65, 0x61,
+	0x6d, 0x73, 0x12, 0x1a, 0x0a, 0x08, 0x73, 0x74, 0x72, 0x61, 0x74, 0x65, 0x67, 0x79, 0x18, 0x05,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x73, 0x74, 0x72, 0x61, 0x74, 0x65, 0x67, 0x79, 0x12, 0x51,
+	0x0a, 0x0f, 0x73, 0x74, 0x72, 0x61, 0x74, 0x65, 0x67, 0x79, 0x5f, 0x70, 0x61, 0x72, 0x61, 0x6d,
+	0x73, 0x18, 0x06, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x28, 0x2e, 0x73, 0x69, 0x6d, 0x2e, 0x50, 0x72,
+	0x6f, 0x66, 0x69, 0x6c, 0x65, 0x53, 0x65, 0x74, 0x74, 0x69, 0x6e, 0x67, 0x73, 0x2e, 0x53, 0x74,
+	0x72, 0x61, 0x74, 0x65, 0x67, 0x79, 0x50, 0x61, 0x72, 0x61, 0x6d, 0x73, 0x45, 0x6e, 0x74, 0x72,
+	0x79, 0x52, 0x0e, 0x73, 0x74, 0x72, 0x61, 0x74, 0x65, 0x67, 0x79, 0x50, 0x61, 0x72, 0x61, 0x6d,
+	0x73, 0x12, 0x36, 0x0a, 0x0b, 0x73, 0x6b, 0x69, 0x6c, 0x6c, 0x5f, 0x63, 0x75, 0x72, 0x76, 0x65,
+	0x18, 0x07, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x15, 0x2e, 0x73, 0x69, 0x6d, 0x2e, 0x53, 0x6b, 0x69,
+	0x6c, 0x6c, 0x57, 0x69, 0x6e, 0x64, 0x6f, 0x77, 0x50, 0x6f, 0x69, 0x6e, 0x74, 0x52, 0x0a, 0x73,
+	0x6b, 0x69, 0x6c, 0x6c, 0x43, 0x75, 0x72, 0x76, 0x65, 0x12, 0x19, 0x0a, 0x08, 0x6d, 0x61, 0x78,
+	0x5f, 0x70, 0x69, 0x6e, 0x67, 0x18, 0x08, 0x20, 0x01, 0x28, 0x01, 0x52, 0x07, 0x6d, 0x61, 0x78,
+	0x50, 0x69, 0x6e, 0x67, 0x12, 0x26, 0x0a, 0x0f, 0x6d, 0x61, 0x78, 0x5f, 0x70, 0x69, 0x6e, 0x67,
+	0x5f, 0x73, 0x70, 0x72, 0x65, 0x61, 0x64, 0x18, 0x09, 0x20, 0x01, 0x28, 0x01, 0x52, 0x0d, 0x6d,
+	0x61, 0x78, 0x50, 0x69, 0x6e, 0x67, 0x53, 0x70, 0x72, 0x65, 0x61, 0x64, 0x12, 0x1a, 0x0a, 0x08,
+	0x62, 0x61, 0x63, 0x6b, 0x66, 0x69, 0x6c, 0x6c, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x08, 0x52, 0x08,
+	0x62, 0x61, 0x63, 0x6b, 0x66, 0x69, 0x6c, 0x6c, 0x12, 0x30, 0x0a, 0x14, 0x62, 0x61, 0x63, 0x6b,
+	0x66, 0x69, 0x6c, 0x6c, 0x5f, 0x6d, 0x69, 0x6e, 0x5f, 0x70, 0x6c, 0x61, 0x79, 0x65, 0x72, 0x73,
+	0x18, 0x0b, 0x20, 0x01, 0x28, 0x05, 0x52, 0x12, 0x62, 0x61, 0x63, 0x6b, 0x66, 0x69, 0x6c, 0x6c,
+	0x4d, 0x69, 0x6e, 0x50, 0x6c, 0x61, 0x79, 0x65, 0x72, 0x73, 0x12, 0x45, 0x0a, 0x11, 0x62, 0x61,
+	0x63, 0x6b, 0x66, 0x69, 0x6c, 0x6c, 0x5f, 0x6d, 0x69, 0x6e, 0x5f, 0x77, 0x61, 0x69, 0x74, 0x18,
+	0x0c, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x44, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e,
+	0x52, 0x0f, 0x62, 0x61, 0x63, 0x6b, 0x66, 0x69, 0x6c, 0x6c, 0x4d, 0x69, 0x6e, 0x57, 0x61, 0x69,
+	0x74, 0x1a, 0x41, 0x0a, 0x13, 0x53, 0x74, 0x72, 0x61, 0x74, 0x65, 0x67, 0x79, 0x50, 0x61, 0x72,
+	0x61, 0x6d, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61,
+	0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65,
+	0x3a, 0x02, 0x38, 0x01, 0x22, 0x73, 0x0a, 0x10, 0x53, 0x6b, 0x69, 0x6c, 0x6c, 0x57, 0x69, 0x6e,
+	0x64, 0x6f, 0x77, 0x50, 0x6f, 0x69, 0x6e, 0x74, 0x12, 0x2d, 0x0a, 0x04, 0x77, 0x61, 0x69, 0x74,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x44, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f,
+	0x6e, 0x52, 0x04, 0x77, 0x61, 0x69, 0x74, 0x12, 0x30, 0x0a, 0x14, 0x6d, 0x61, 0x78, 0x5f, 0x73,
+	0x6b, 0x69, 0x6c, 0x6c, 0x5f, 0x64, 0x69, 0x66, 0x66, 0x65, 0x72, 0x65, 0x6e, 0x63, 0x65, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x01, 0x52, 0x12, 0x6d, 0x61, 0x78, 0x53, 0x6b, 0x69, 0x6c, 0x6c, 0x44,
+	0x69, 0x66, 0x66, 0x65, 0x72, 0x65, 0x6e, 0x63, 0x65, 0x22, 0x80, 0x02, 0x0a, 0x0c, 0x4d, 0x61,
+	0x74, 0x63, 0x68, 0x51, 0x75, 0x61, 0x6c, 0x69, 0x74, 0x79, 0x12, 0x1f, 0x0a, 0x0b, 0x6e, 0x75,
+	0x6d, 0x5f, 0x74, 0x69, 0x63, 0x6b, 0x65, 0x74, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52,
+	0x0a, 0x6e, 0x75, 0x6d, 0x54, 0x69, 0x63, 0x6b, 0x65, 0x74, 0x73, 0x12, 0x1f, 0x0a, 0x0b, 0x6e,
+	0x75, 0x6d, 0x5f, 0x70, 0x6c, 0x61, 0x79, 0x65, 0x72, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05,
+	0x52, 0x0a, 0x6e, 0x75, 0x6d, 0x50, 0x6c, 0x61, 0x79, 0x65, 0x72, 0x73, 0x12, 0x14, 0x0a, 0x05,
+	0x69, 0x6e, 0x64, 0x65, 0x78, 0x18, 0x03, 0x20, 0x01, 0x28, 0x05, 0x52, 0x05, 0x69, 0x6e, 0x64,
+	0x65, 0x78, 0x12, 0x34, 0x0a, 0x08, 0x6d, 0x61, 0x78, 0x5f, 0x77, 0x61, 0x69, 0x74, 0x18, 0x04,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72,
+	0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x44, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52,
+	0x07, 0x6d, 0x61, 0x78, 0x57, 0x61, 0x69, 0x74, 0x12, 0x23, 0x0a, 0x0d, 0x73, 0x6b, 0x69, 0x6c,
+	0x6c, 0x5f, 0x71, 0x75, 0x61, 0x6c, 0x69, 0x74, 0x79, 0x18, 0x05, 0x20, 0x01, 0x28, 0x01, 0x52,
+	0x0c, 0x73, 0x6b, 0x69, 0x6c, 0x6c, 0x51, 0x75, 0x61, 0x6c, 0x69, 0x74, 0x79, 0x12, 0x27, 0x0a,
+	0x0f, 0x6c, 0x61, 0x74, 0x65, 0x6e, 0x63, 0x79, 0x5f, 0x71, 0x75, 0x61, 0x6c, 0x69, 0x74, 0x79,
+	0x18, 0x06, 0x20, 0x01, 0x28, 0x01, 0x52, 0x0e, 0x6c, 0x61, 0x74, 0x65, 0x6e, 0x63, 0x79, 0x51,
+	0x75, 0x61, 0x6c, 0x69, 0x74, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x73, 0x63, 0x6f, 0x72, 0x65, 0x18,
+	0x07, 0x20, 0x01, 0x28, 0x01, 0x52, 0x05, 0x73, 0x63, 0x6f, 0x72, 0x65, 0x22, 0x6b, 0x0a, 0x04,
+	0x54, 0x65, 0x61, 0x6d, 0x12, 0x14, 0x0a, 0x05, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x05, 0x52, 0x05, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x12, 0x1d, 0x0a, 0x0a, 0x74, 0x69,
+	0x63, 0x6b, 0x65, 0x74, 0x5f, 0x69, 0x64, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x09, 0x52, 0x09,
+	0x74, 0x69, 0x63, 0x6b, 0x65, 0x74, 0x49, 0x64, 0x73, 0x12, 0x18, 0x0a, 0x07, 0x70, 0x6c, 0x61,
+	0x79, 0x65, 0x72, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x05, 0x52, 0x07, 0x70, 0x6c, 0x61, 0x79,
+	0x65, 0x72, 0x73, 0x12, 0x14, 0x0a, 0x05, 0x73, 0x6b, 0x69, 0x6c, 0x6c, 0x18, 0x04, 0x20, 0x01,
+	0x28, 0x01, 0x52, 0x05, 0x73, 0x6b, 0x69, 0x6c, 0x6c, 0x22, 0x2d, 0x0a, 0x0a, 0x54, 0x65, 0x61,
+	0x6d, 0x4c, 0x61, 0x79, 0x6f, 0x75, 0x74, 0x12, 0x1f, 0x0a, 0x05, 0x74, 0x65, 0x61, 0x6d, 0x73,
+	0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x09, 0x2e, 0x73, 0x69, 0x6d, 0x2e, 0x54, 0x65, 0x61,
+	0x6d, 0x52, 0x05, 0x74, 0x65, 0x61, 0x6d, 0x73, 0x22, 0x65, 0x0a, 0x0d, 0x42, 0x61, 0x63, 0x6b,
+	0x66, 0x69, 0x6c, 0x6c, 0x53, 0x74, 0x61, 0x74, 0x65, 0x12, 0x1f, 0x0a, 0x0b, 0x6d, 0x61, 0x78,
+	0x5f, 0x70, 0x6c, 0x61, 0x79, 0x65, 0x72, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0a,
+	0x6d, 0x61, 0x78, 0x50, 0x6c, 0x61, 0x79, 0x65, 0x72, 0x73, 0x12, 0x1d, 0x0a, 0x0a, 0x6f, 0x70,
+	0x65, 0x6e, 0x5f, 0x73, 0x6c, 0x6f, 0x74, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x09,
+	0x6f, 0x70, 0x65, 0x6e, 0x53, 0x6c, 0x6f, 0x74, 0x73, 0x12, 0x14, 0x0a, 0x05, 0x73, 0x6b, 0x69,
+	0x6c, 0x6c, 0x18, 0x03, 0x20, 0x01, 0x28, 0x01, 0x52, 0x05, 0x73, 0x6b, 0x69, 0x6c, 0x6c, 0x42,
+	0x10, 0x5a, 0x0e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2f, 0x73, 0x69, 0x6d, 0x70, 0x72, 0x6f, 0x74,
+	0x6f, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
 }
 
 var (
@@ -93,16 +810,36 @@ func file_proto_sim_proto_rawDescGZIP() []byte {
 	return file_proto_sim_proto_rawDescData
 }
 
-var file_proto_sim_proto_msgTypes = make([]protoimpl.MessageInfo, 1)
+var file_proto_sim_proto_msgTypes = make([]protoimpl.MessageInfo, 10)
 var file_proto_sim_proto_goTypes = []interface{}{
-	(*DefaultEvaluationString)(nil), // 0: sim.DefaultEvaluationString
+	(*RegionLatencies)(nil),       // 0: sim.RegionLatencies
+	(*PlayerProfile)(nil),         // 1: sim.PlayerProfile
+	(*ProfileSettings)(nil),       // 2: sim.ProfileSettings
+	(*SkillWindowPoint)(nil),      // 3: sim.SkillWindowPoint
+	(*MatchQuality)(nil),          // 4: sim.MatchQuality
+	(*Team)(nil),                  // 5: sim.Team
+	(*TeamLayout)(nil),            // 6: sim.TeamLayout
+	(*BackfillState)(nil),         // 7: sim.BackfillState
+	nil,                           // 8: sim.RegionLatencies.PingsEntry
+	nil,                           // 9: sim.ProfileSettings.StrategyParamsEntry
+	(*timestamppb.Timestamp)(nil), // 10: google.protobuf.Timestamp
+	(*durationpb.Duration)(nil),   // 11: google.protobuf.Duration
 }
 var file_proto_sim_proto_depIdxs = []int32{
-	0, // [0:0] is the sub-list for method output_type
-	0, // [0:0] is the sub-list for method input_type
-	0, // [0:0] is the sub-list for extension type_name
-	0, // [0:0] is the sub-list for extension extendee
-	0, // [0:0] is the sub-list for field type_name
+	8,  // 0: sim.RegionLatencies.pings:type_name -> sim.RegionLatencies.PingsEntry
+	0,  // 1: sim.PlayerProfile.latencies:type_name -> sim.RegionLatencies
+	10, // 2: sim.PlayerProfile.original_create_time:type_name -> google.protobuf.Timestamp
+	9,  // 3: sim.ProfileSettings.strategy_params:type_name -> sim.ProfileSettings.StrategyParamsEntry
+	3,  // 4: sim.ProfileSettings.skill_curve:type_name -> sim.SkillWindowPoint
+	11, // 5: sim.ProfileSettings.backfill_min_wait:type_name -> google.protobuf.Duration
+	11, // 6: sim.SkillWindowPoint.wait:type_name -> google.protobuf.Duration
+	11, // 7: sim.MatchQuality.max_wait:type_name -> google.protobuf.Duration
+	5,  // 8: sim.TeamLayout.teams:type_name -> sim.Team
+	9,  // [9:9] is the sub-list for method output_type
+	9,  // [9:9] is the sub-list for method input_type
+	9,  // [9:9] is the sub-list for extension type_name
+	9,  // [9:9] is the sub-list for extension extendee
+	0,  // [0:9] is the sub-list for field type_name
 }
 
 func init() { file_proto_sim_proto_init() }
@@ -112,7 +849,91 @@ func file_proto_sim_proto_init() {
 	}
 	if !protoimpl.UnsafeEnabled {
 		file_proto_sim_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*DefaultEvaluationString); i {
+			switch v := v.(*RegionLatencies); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_sim_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*PlayerProfile); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_sim_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ProfileSettings); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_sim_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SkillWindowPoint); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_sim_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*MatchQuality); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_sim_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Team); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_sim_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*TeamLayout); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_sim_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*BackfillState); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -130,7 +951,7 @@ func file_proto_sim_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_proto_sim_proto_rawDesc,
 			NumEnums:      0,
-			NumMessages:   1,
+			NumMessages:   10,
 			NumExtensions: 0,
 			NumServices:   0,
 		},